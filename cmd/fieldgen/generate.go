@@ -0,0 +1,628 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	gormSchema "gorm.io/gorm/schema"
+)
+
+// backend selects how stored field names are derived
+type backend string
+
+const (
+	// auto treats structs with bson tags as MongoDB entities and others as PostgreSQL entities
+	auto     backend = "auto"
+	mongo    backend = "mongo"
+	postgres backend = "postgres"
+)
+
+// fieldPackage is the import path of the typed field references
+const fieldPackage = "github.com/arash-mosavi/go-base-repository/pkg/field"
+
+// gormPackage is the import path of GORM, whose Model is flattened into PostgreSQL entities
+const gormPackage = "gorm.io/gorm"
+
+// entityField is a generated field reference
+type entityField struct {
+	goName    string
+	stored    string
+	valueType string
+	text      bool
+}
+
+// declaration is a type declared in the package or in one it imports, with the file it is
+// declared in
+type declaration struct {
+	spec *ast.TypeSpec
+	file *ast.File
+	// pkg is the imported package declaring the type, nil for the package itself
+	pkg *importedPackage
+}
+
+// importedPackage is a package whose structs are embedded or inlined into entities
+type importedPackage struct {
+	// name is the name the package is referred to by in the generated file
+	name string
+	// spec is the import declaration of the package
+	spec  string
+	types map[string]declaration
+}
+
+// loader parses the Go files of the package with the given import path
+type loader func(path string) ([]*ast.File, error)
+
+// generator collects the fields of entity structs and the imports their types need
+type generator struct {
+	types    map[string]declaration
+	imports  map[string]string
+	load     loader
+	packages map[string]*importedPackage
+}
+
+// generate returns the source of a file in package pkg declaring typed field references for the
+// named struct types of files, naming their fields as kind does. Structs embedded from other
+// packages are parsed through load.
+func generate(pkg string, files []*ast.File, names []string, kind backend, load loader) ([]byte, error) {
+	g := &generator{
+		types:    declarations(files, nil),
+		imports:  make(map[string]string),
+		load:     load,
+		packages: make(map[string]*importedPackage),
+	}
+
+	var body bytes.Buffer
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		decl, ok := g.types[name]
+		if !ok {
+			return nil, fmt.Errorf("type %s not found", name)
+		}
+		if _, ok := decl.spec.Type.(*ast.StructType); !ok {
+			return nil, fmt.Errorf("type %s is not a struct", name)
+		}
+
+		entityBackend := kind
+		if entityBackend == auto {
+			entityBackend = detectBackend(decl)
+		}
+
+		var fields []entityField
+		var err error
+		switch entityBackend {
+		case mongo:
+			fields, err = g.bsonFields(decl)
+		case postgres:
+			fields, err = g.columnFields(decl, "")
+		default:
+			return nil, fmt.Errorf("unknown backend %q", kind)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("type %s: %w", name, err)
+		}
+
+		writeFields(&body, name, dedupe(fields))
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by fieldgen; DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+	g.imports["field"] = strconv.Quote(fieldPackage)
+	standard, external := sortedImports(g.imports)
+	for _, spec := range standard {
+		fmt.Fprintf(&src, "\t%s\n", spec)
+	}
+	if len(standard) > 0 {
+		src.WriteString("\n")
+	}
+	for _, spec := range external {
+		fmt.Fprintf(&src, "\t%s\n", spec)
+	}
+	src.WriteString(")\n")
+	src.Write(body.Bytes())
+
+	formatted, err := format.Source(src.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+// declarations returns the types declared in files, by name
+func declarations(files []*ast.File, pkg *importedPackage) map[string]declaration {
+	types := make(map[string]declaration)
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+					types[typeSpec.Name.Name] = declaration{spec: typeSpec, file: file, pkg: pkg}
+				}
+			}
+		}
+	}
+	return types
+}
+
+// writeFields writes the declaration of the field references of the entity name
+func writeFields(w *bytes.Buffer, name string, fields []entityField) {
+	fmt.Fprintf(w, "\n// %sFields are the typed fields of %s\n", name, name)
+	fmt.Fprintf(w, "var %sFields = struct {\n", name)
+	for _, f := range fields {
+		fmt.Fprintf(w, "\t%s field.%s[%s]\n", f.goName, f.kind(), f.valueType)
+	}
+	w.WriteString("}{\n")
+	for _, f := range fields {
+		fmt.Fprintf(w, "\t%s: field.New%s[%s](%q),\n", f.goName, strings.TrimPrefix(f.kind(), "Field"), f.valueType, f.stored)
+	}
+	w.WriteString("}\n")
+}
+
+// kind returns the field package type referencing f
+func (f entityField) kind() string {
+	if f.text {
+		return "Text"
+	}
+	return "Field"
+}
+
+// bsonFields returns the fields of the struct decl named as the MongoDB driver encodes them
+func (g *generator) bsonFields(decl declaration) ([]entityField, error) {
+	var fields []entityField
+	for _, f := range decl.spec.Type.(*ast.StructType).Fields.List {
+		for _, sf := range structFields(f) {
+			if !ast.IsExported(sf.Name) {
+				continue
+			}
+
+			tags, err := bsoncodec.DefaultStructTagParser.ParseStructTags(sf)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+			}
+			if tags.Skip {
+				continue
+			}
+
+			if tags.Inline {
+				inner, ok, err := g.structType(decl, f.Type)
+				if err != nil {
+					return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+				}
+				if !ok {
+					// inline maps hold arbitrary fields
+					if _, isMap := deref(f.Type).(*ast.MapType); isMap {
+						continue
+					}
+					return nil, fmt.Errorf("field %s: cannot inline %s", sf.Name, types.ExprString(f.Type))
+				}
+				nested, err := g.bsonFields(inner)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, nested...)
+				continue
+			}
+
+			field, err := g.field(decl, sf.Name, tags.Name, f.Type)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// columnFields returns the fields of the struct decl named as GORM names their columns.
+// Embedded structs are flattened and relationships skipped.
+func (g *generator) columnFields(decl declaration, prefix string) ([]entityField, error) {
+	naming := gormSchema.NamingStrategy{}
+
+	var fields []entityField
+	for _, f := range decl.spec.Type.(*ast.StructType).Fields.List {
+		for _, sf := range structFields(f) {
+			if !ast.IsExported(sf.Name) {
+				continue
+			}
+
+			settings := gormSchema.ParseTagSetting(sf.Tag.Get("gorm"), ";")
+			if _, ok := settings["-"]; ok {
+				continue
+			}
+			if isRelationship(settings) {
+				continue
+			}
+
+			if _, embedded := settings["EMBEDDED"]; embedded || sf.Anonymous {
+				if gorm, ok := g.gormModel(decl.file, f.Type); ok {
+					fields = append(fields, gormModelFields(prefix, gorm)...)
+					continue
+				}
+				inner, ok, err := g.structType(decl, f.Type)
+				if err != nil {
+					return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+				}
+				if !ok {
+					return nil, fmt.Errorf("field %s: cannot embed %s", sf.Name, types.ExprString(f.Type))
+				}
+				nested, err := g.columnFields(inner, prefix+settings["EMBEDDEDPREFIX"])
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, nested...)
+				continue
+			}
+
+			if g.isLocalStructOrList(decl, f.Type) {
+				// a struct of the package, or a list of them, is an association
+				continue
+			}
+
+			column := settings["COLUMN"]
+			if column == "" {
+				column = naming.ColumnName("", sf.Name)
+			}
+
+			field, err := g.field(decl, sf.Name, prefix+column, f.Type)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// field returns the reference to a field of decl stored as stored, recording the imports its
+// type needs
+func (g *generator) field(decl declaration, goName, stored string, expr ast.Expr) (entityField, error) {
+	valueType := deref(expr)
+	if err := g.addImports(decl.file, valueType); err != nil {
+		return entityField{}, fmt.Errorf("field %s: %w", goName, err)
+	}
+	text := g.isText(decl, valueType)
+
+	if decl.pkg != nil {
+		var err error
+		if valueType, err = g.qualify(decl.pkg, valueType); err != nil {
+			return entityField{}, fmt.Errorf("field %s: %w", goName, err)
+		}
+	}
+
+	return entityField{
+		goName:    goName,
+		stored:    stored,
+		valueType: types.ExprString(valueType),
+		text:      text,
+	}, nil
+}
+
+// qualify returns a copy of expr, a type written in the imported package pkg, with the types
+// pkg declares referred to through its package name
+func (g *generator) qualify(pkg *importedPackage, expr ast.Expr) (ast.Expr, error) {
+	var err error
+	each := func(e ast.Expr) ast.Expr {
+		if e == nil || err != nil {
+			return e
+		}
+		var qualified ast.Expr
+		qualified, err = g.qualify(pkg, e)
+		return qualified
+	}
+
+	var qualified ast.Expr
+	switch t := expr.(type) {
+	case *ast.Ident:
+		if _, ok := pkg.types[t.Name]; !ok {
+			return t, nil
+		}
+		if !ast.IsExported(t.Name) {
+			return nil, fmt.Errorf("type %s is not exported by package %s", t.Name, pkg.name)
+		}
+		g.imports[pkg.name] = pkg.spec
+		return &ast.SelectorExpr{X: ast.NewIdent(pkg.name), Sel: ast.NewIdent(t.Name)}, nil
+	case *ast.StarExpr:
+		qualified = &ast.StarExpr{X: each(t.X)}
+	case *ast.ArrayType:
+		qualified = &ast.ArrayType{Len: t.Len, Elt: each(t.Elt)}
+	case *ast.MapType:
+		qualified = &ast.MapType{Key: each(t.Key), Value: each(t.Value)}
+	case *ast.IndexExpr:
+		qualified = &ast.IndexExpr{X: each(t.X), Index: each(t.Index)}
+	default:
+		return expr, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return qualified, nil
+}
+
+// addImports records the imports of file that the package selectors in expr refer to
+func (g *generator) addImports(file *ast.File, expr ast.Expr) error {
+	var err error
+	ast.Inspect(expr, func(n ast.Node) bool {
+		selector, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		pkg, ok := selector.X.(*ast.Ident)
+		if !ok {
+			return true
+		}
+
+		spec, found := importSpec(file, pkg.Name)
+		if !found {
+			err = fmt.Errorf("no import for package %s", pkg.Name)
+			return false
+		}
+		g.imports[pkg.Name] = spec
+		return false
+	})
+	return err
+}
+
+// importSpec returns the import declaration of file that binds name
+func importSpec(file *ast.File, name string) (string, bool) {
+	for _, imp := range file.Imports {
+		path, _ := strconv.Unquote(imp.Path.Value)
+		if imp.Name != nil {
+			if imp.Name.Name == name {
+				return imp.Name.Name + " " + imp.Path.Value, true
+			}
+			continue
+		}
+		if packageName(path) == name {
+			return imp.Path.Value, true
+		}
+	}
+	return "", false
+}
+
+// packageName guesses the name of the package at path from its last element, skipping a major
+// version suffix
+func packageName(path string) string {
+	elements := strings.Split(path, "/")
+	name := elements[len(elements)-1]
+	if len(elements) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elements[len(elements)-2]
+	}
+	return strings.TrimPrefix(strings.TrimSuffix(name, ".go"), "go-")
+}
+
+// isText reports whether expr, a type written in the package of from, is string or a string type
+// declared in that package
+func (g *generator) isText(from declaration, expr ast.Expr) bool {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	if ident.Name == "string" {
+		return true
+	}
+	decl, ok := g.packageTypes(from)[ident.Name]
+	return ok && decl.spec.Assign == 0 && g.isText(decl, decl.spec.Type)
+}
+
+// packageTypes returns the types declared in the package of decl
+func (g *generator) packageTypes(decl declaration) map[string]declaration {
+	if decl.pkg != nil {
+		return decl.pkg.types
+	}
+	return g.types
+}
+
+// structType returns the struct that expr, a type written in the package of from, names: one
+// declared in that package, or one of a package it imports
+func (g *generator) structType(from declaration, expr ast.Expr) (declaration, bool, error) {
+	var decl declaration
+	var ok bool
+	switch t := deref(expr).(type) {
+	case *ast.Ident:
+		decl, ok = g.packageTypes(from)[t.Name]
+	case *ast.SelectorExpr:
+		name, isIdent := t.X.(*ast.Ident)
+		if !isIdent {
+			return declaration{}, false, nil
+		}
+		pkg, err := g.importedPackage(from.file, name.Name)
+		if err != nil {
+			return declaration{}, false, err
+		}
+		if pkg == nil {
+			return declaration{}, false, nil
+		}
+		decl, ok = pkg.types[t.Sel.Name]
+	}
+	if !ok {
+		return declaration{}, false, nil
+	}
+	_, isStruct := decl.spec.Type.(*ast.StructType)
+	return decl, isStruct, nil
+}
+
+// importedPackage loads the package file imports as name, returning nil when file has no such
+// import or no loader is set
+func (g *generator) importedPackage(file *ast.File, name string) (*importedPackage, error) {
+	spec, found := importSpec(file, name)
+	if !found || g.load == nil {
+		return nil, nil
+	}
+	path := importPath(spec)
+	if pkg, ok := g.packages[path]; ok {
+		return pkg, nil
+	}
+
+	files, err := g.load(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load package %s: %w", path, err)
+	}
+	pkg := &importedPackage{name: name, spec: spec}
+	pkg.types = declarations(files, pkg)
+	g.packages[path] = pkg
+	return pkg, nil
+}
+
+// isLocalStructOrList reports whether expr is a struct declared in the package of from or a list
+// of them
+func (g *generator) isLocalStructOrList(from declaration, expr ast.Expr) bool {
+	expr = deref(expr)
+	if list, ok := expr.(*ast.ArrayType); ok {
+		expr = list.Elt
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	decl, ok := g.packageTypes(from)[ident.Name]
+	if !ok {
+		return false
+	}
+	_, isStruct := decl.spec.Type.(*ast.StructType)
+	return isStruct
+}
+
+// gormModel reports whether expr is gorm.Model, returning the name GORM is imported as
+func (g *generator) gormModel(file *ast.File, expr ast.Expr) (string, bool) {
+	selector, ok := deref(expr).(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != "Model" {
+		return "", false
+	}
+	pkg, ok := selector.X.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	spec, ok := importSpec(file, pkg.Name)
+	if !ok || !strings.HasSuffix(spec, strconv.Quote(gormPackage)) {
+		return "", false
+	}
+
+	g.imports[pkg.Name] = spec
+	g.imports["time"] = strconv.Quote("time")
+	return pkg.Name, true
+}
+
+// gormModelFields returns the fields of gorm.Model, whose package is imported as gorm
+func gormModelFields(prefix, gorm string) []entityField {
+	return []entityField{
+		{goName: "ID", stored: prefix + "id", valueType: "uint"},
+		{goName: "CreatedAt", stored: prefix + "created_at", valueType: "time.Time"},
+		{goName: "UpdatedAt", stored: prefix + "updated_at", valueType: "time.Time"},
+		{goName: "DeletedAt", stored: prefix + "deleted_at", valueType: gorm + ".DeletedAt"},
+	}
+}
+
+// isRelationship reports whether the GORM tag settings declare an association
+func isRelationship(settings map[string]string) bool {
+	for _, key := range []string{"FOREIGNKEY", "REFERENCES", "MANY2MANY", "POLYMORPHIC"} {
+		if _, ok := settings[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// detectBackend treats a struct with bson tags as a MongoDB entity
+func detectBackend(decl declaration) backend {
+	for _, f := range decl.spec.Type.(*ast.StructType).Fields.List {
+		for _, sf := range structFields(f) {
+			if _, ok := sf.Tag.Lookup("bson"); ok {
+				return mongo
+			}
+		}
+	}
+	return postgres
+}
+
+// structFields returns the reflect form of the names and tag of f; an embedded field is named
+// after its type
+func structFields(f *ast.Field) []reflect.StructField {
+	var tag reflect.StructTag
+	if f.Tag != nil {
+		unquoted, _ := strconv.Unquote(f.Tag.Value)
+		tag = reflect.StructTag(unquoted)
+	}
+
+	if len(f.Names) == 0 {
+		var name string
+		switch t := deref(f.Type).(type) {
+		case *ast.Ident:
+			name = t.Name
+		case *ast.SelectorExpr:
+			name = t.Sel.Name
+		}
+		return []reflect.StructField{{Name: name, Tag: tag, Anonymous: true}}
+	}
+
+	fields := make([]reflect.StructField, len(f.Names))
+	for i, name := range f.Names {
+		fields[i] = reflect.StructField{Name: name.Name, Tag: tag}
+	}
+	return fields
+}
+
+// deref removes the pointers from expr
+func deref(expr ast.Expr) ast.Expr {
+	for {
+		star, ok := expr.(*ast.StarExpr)
+		if !ok {
+			return expr
+		}
+		expr = star.X
+	}
+}
+
+// dedupe drops fields whose Go or stored name an earlier field already has, as the encoders do
+// for promoted fields
+func dedupe(fields []entityField) []entityField {
+	goNames := make(map[string]bool)
+	stored := make(map[string]bool)
+
+	unique := fields[:0]
+	for _, f := range fields {
+		if goNames[f.goName] || stored[f.stored] {
+			continue
+		}
+		goNames[f.goName] = true
+		stored[f.stored] = true
+		unique = append(unique, f)
+	}
+	return unique
+}
+
+// sortedImports returns the import declarations of the standard library and of other modules,
+// each in path order
+func sortedImports(imports map[string]string) ([]string, []string) {
+	var standard, external []string
+	for _, spec := range imports {
+		first, _, _ := strings.Cut(importPath(spec), "/")
+		if strings.Contains(first, ".") {
+			external = append(external, spec)
+		} else {
+			standard = append(standard, spec)
+		}
+	}
+
+	byPath := func(specs []string) func(i, j int) bool {
+		return func(i, j int) bool { return importPath(specs[i]) < importPath(specs[j]) }
+	}
+	sort.Slice(standard, byPath(standard))
+	sort.Slice(external, byPath(external))
+	return standard, external
+}
+
+// importPath returns the path of an import declaration
+func importPath(spec string) string {
+	path, _ := strconv.Unquote(spec[strings.Index(spec, `"`):])
+	return path
+}
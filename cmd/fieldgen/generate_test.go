@@ -0,0 +1,221 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+const entitySource = `package models
+
+import (
+	"time"
+
+	oid "go.mongodb.org/mongo-driver/bson/primitive"
+	"gorm.io/gorm"
+)
+
+type Status string
+
+type Audit struct {
+	CreatedAt time.Time ` + "`bson:\"createdAt\"`" + `
+}
+
+type Address struct {
+	City string
+}
+
+type Article struct {
+	ID       oid.ObjectID      ` + "`bson:\"_id,omitempty\"`" + `
+	Title    string            ` + "`bson:\"title\"`" + `
+	Status   Status            ` + "`bson:\"status\"`" + `
+	Views    *int64            ` + "`bson:\"views,omitempty\"`" + `
+	Tags     []string          ` + "`bson:\"tags\"`" + `
+	Audit    Audit             ` + "`bson:\",inline\"`" + `
+	Extra    map[string]string ` + "`bson:\",inline\"`" + `
+	Internal string            ` + "`bson:\"-\"`" + `
+	Untagged bool
+	secret   string
+}
+
+type Author struct {
+	gorm.Model
+	Email   string  ` + "`gorm:\"column:email_address\"`" + `
+	Home    Address ` + "`gorm:\"embedded;embeddedPrefix:home_\"`" + `
+	Posts   []Post
+	Profile *Profile
+	Skipped string ` + "`gorm:\"-\"`" + `
+	UserID  int
+}
+
+type Post struct {
+	ID int
+}
+
+type Profile struct {
+	ID int
+}
+`
+
+// parseEntities parses entitySource
+func parseEntities(t *testing.T) []*ast.File {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "models.go", entitySource, parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatalf("Failed to parse source: %v", err)
+	}
+	return []*ast.File{file}
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := generate("models", parseEntities(t), []string{"Article", "Author"}, auto, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	out := string(src)
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "fields.go", src, 0); err != nil {
+		t.Fatalf("Generated code does not parse: %v\n%s", err, out)
+	}
+
+	expected := []string{
+		"// Code generated by fieldgen; DO NOT EDIT.",
+		"import (\n\t\"time\"\n\n\t\"github.com/arash-mosavi/go-base-repository/pkg/field\"\n\toid \"go.mongodb.org/mongo-driver/bson/primitive\"\n\t\"gorm.io/gorm\"\n)",
+		`ID:        field.New[oid.ObjectID]("_id")`,
+		`Title:     field.NewText[string]("title")`,
+		`Status:    field.NewText[Status]("status")`,
+		`Views:     field.New[int64]("views")`,
+		`Tags:      field.New[[]string]("tags")`,
+		`CreatedAt: field.New[time.Time]("createdAt")`,
+		`Untagged:  field.New[bool]("untagged")`,
+		`ID:        field.New[uint]("id")`,
+		`DeletedAt: field.New[gorm.DeletedAt]("deleted_at")`,
+		`Email:     field.NewText[string]("email_address")`,
+		`City:      field.NewText[string]("home_city")`,
+		`UserID:    field.New[int]("user_id")`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	for _, unwanted := range []string{"Internal", "Extra", "secret", "Posts", "Profile", "Skipped"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("Expected generated code not to contain %q, got:\n%s", unwanted, out)
+		}
+	}
+}
+
+func TestGenerate_ExplicitBackend(t *testing.T) {
+	src, err := generate("models", parseEntities(t), []string{"Article"}, postgres, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !strings.Contains(string(src), `ID:       field.New[oid.ObjectID]("id")`) ||
+		!strings.Contains(string(src), `Untagged: field.New[bool]("untagged")`) {
+		t.Errorf("Expected column names, got:\n%s", src)
+	}
+}
+
+const sharedSource = `package base
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Kind string
+
+type Entity struct {
+	ID        primitive.ObjectID ` + "`bson:\"_id,omitempty\"`" + `
+	Kind      Kind               ` + "`bson:\"kind\"`" + `
+	Labels    map[string]Kind    ` + "`bson:\"labels\"`" + `
+	CreatedAt time.Time          ` + "`bson:\"createdAt\"`" + `
+}
+
+type Record struct {
+	ID   uint
+	Kind *Kind
+}
+`
+
+const embeddingSource = `package models
+
+import (
+	shared "example.com/app/base"
+)
+
+type Note struct {
+	shared.Entity ` + "`bson:\",inline\"`" + `
+	Body          string ` + "`bson:\"body\"`" + `
+}
+
+type Row struct {
+	shared.Record
+	Body string
+}
+`
+
+func TestGenerate_ImportedEmbeddedStructs(t *testing.T) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "models.go", embeddingSource, parser.SkipObjectResolution)
+	if err != nil {
+		t.Fatalf("Failed to parse source: %v", err)
+	}
+	load := func(path string) ([]*ast.File, error) {
+		if path != "example.com/app/base" {
+			t.Fatalf("Expected example.com/app/base to be loaded, got %s", path)
+		}
+		shared, err := parser.ParseFile(fset, "base.go", sharedSource, parser.SkipObjectResolution)
+		return []*ast.File{shared}, err
+	}
+
+	src, err := generate("models", []*ast.File{file}, []string{"Note", "Row"}, auto, load)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	out := string(src)
+
+	expected := []string{
+		"import (\n\t\"time\"\n\n\tshared \"example.com/app/base\"\n\t\"github.com/arash-mosavi/go-base-repository/pkg/field\"\n\t\"go.mongodb.org/mongo-driver/bson/primitive\"\n)",
+		`ID:        field.New[primitive.ObjectID]("_id")`,
+		`Kind:      field.NewText[shared.Kind]("kind")`,
+		`Labels:    field.New[map[string]shared.Kind]("labels")`,
+		`CreatedAt: field.New[time.Time]("createdAt")`,
+		`Body:      field.NewText[string]("body")`,
+		`ID:   field.New[uint]("id")`,
+		`Kind: field.NewText[shared.Kind]("kind")`,
+		`Body: field.NewText[string]("body")`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected generated code to contain %q, got:\n%s", want, out)
+		}
+	}
+
+	if _, err := generate("models", []*ast.File{file}, []string{"Note"}, auto, nil); err == nil {
+		t.Error("Expected an error without a loader")
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		types []string
+	}{
+		{"unknown type", []string{"Missing"}},
+		{"not a struct", []string{"Status"}},
+	}
+
+	for _, tt := range tests {
+		if _, err := generate("models", parseEntities(t), tt.types, auto, nil); err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Command fieldgen generates typed field references for entity structs, so conditions such as
+//
+//	UserFields.Email.Eq("a@b.c")
+//	UserFields.Age.Gt(18)
+//
+// name the right bson key or column and only accept values of the field's type. Run it through
+// go generate next to the entity:
+//
+//	//go:generate go run github.com/arash-mosavi/go-base-repository/cmd/fieldgen -type User
+//
+// For each type T it declares TFields in <type>_fields.go, named after the first type. Fields
+// are named by their bson tags for MongoDB entities and by GORM's column naming for PostgreSQL
+// entities; -backend picks one explicitly, otherwise a struct with bson tags is a MongoDB entity.
+// Structs embedded or inlined from other packages, such as a shared base entity, are read from
+// the source of those packages.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("fieldgen: ")
+
+	typeNames := flag.String("type", "", "comma-separated list of entity type names; required")
+	backendFlag := flag.String("backend", string(auto), "field naming: auto, mongo or postgres")
+	output := flag.String("output", "", "output file name; default <type>_fields.go")
+	flag.Parse()
+
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	if args := flag.Args(); len(args) > 0 {
+		dir = args[0]
+	}
+
+	names := strings.Split(*typeNames, ",")
+	src, err := run(dir, names, backend(*backendFlag))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	file := *output
+	if file == "" {
+		file = strings.ToLower(names[0]) + "_fields.go"
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(dir, file)
+	}
+	if err := os.WriteFile(file, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run parses the package in dir and generates the field references of names
+func run(dir string, names []string, kind backend) ([]byte, error) {
+	pkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load package: %w", err)
+	}
+
+	fset := token.NewFileSet()
+	files, err := parseFiles(fset, pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	load := func(path string) ([]*ast.File, error) {
+		imported, err := build.Import(path, pkg.Dir, 0)
+		if err != nil {
+			return nil, err
+		}
+		return parseFiles(fset, imported)
+	}
+
+	return generate(pkg.Name, files, names, kind, load)
+}
+
+// parseFiles parses the Go files of pkg
+func parseFiles(fset *token.FileSet, pkg *build.Package) ([]*ast.File, error) {
+	files := make([]*ast.File, 0, len(pkg.GoFiles))
+	for _, name := range pkg.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
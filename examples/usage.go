@@ -11,6 +11,8 @@ import (
 	"gorm.io/gorm"
 )
 
+//go:generate go run ../cmd/fieldgen -type MongoUser,PostgresUser
+
 // MongoUser represents a MongoDB user entity
 type MongoUser struct {
 	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
@@ -98,6 +100,12 @@ func (r *MongoUserRepository) FindActiveUsers(ctx context.Context) ([]*MongoUser
 	return r.baseRepo.FindAll(ctx, filter)
 }
 
+// FindAdults finds the active users of at least 18, using the generated field references
+func (r *MongoUserRepository) FindAdults(ctx context.Context) ([]*MongoUser, error) {
+	filter := identifier.New().And(MongoUserFields.Active.Eq(true), MongoUserFields.Age.Gte(18))
+	return r.baseRepo.FindAll(ctx, filter)
+}
+
 // PostgresUserRepository demonstrates composition-based repository
 type PostgresUserRepository struct {
 	baseRepo interfaces.PostgresBaseRepository[*PostgresUser]
@@ -133,6 +141,12 @@ func (r *PostgresUserRepository) FindActiveUsers(ctx context.Context) ([]*Postgr
 	return r.baseRepo.FindAll(ctx, filter)
 }
 
+// FindAdults finds the active users of at least 18, using the generated field references
+func (r *PostgresUserRepository) FindAdults(ctx context.Context) ([]*PostgresUser, error) {
+	filter := identifier.New().And(PostgresUserFields.Active.Eq(true), PostgresUserFields.Age.Gte(18))
+	return r.baseRepo.FindAll(ctx, filter)
+}
+
 // ExampleUsage demonstrates how to use the base repository SDK
 func ExampleUsage() error {
 	ctx := context.Background()
@@ -5,7 +5,9 @@ go 1.24.0
 require (
 	github.com/arash-mosavi/mongo-unit-of-work-system v1.0.2
 	github.com/arash-mosavi/postgrs-unit-of-work-system v1.0.2
+	github.com/jackc/pgx/v5 v5.7.5
 	go.mongodb.org/mongo-driver v1.17.3
+	gorm.io/driver/postgres v1.6.0
 	gorm.io/gorm v1.30.0
 )
 
@@ -13,7 +15,6 @@ require (
 	github.com/golang/snappy v0.0.4 // indirect
 	github.com/jackc/pgpassfile v1.0.0 // indirect
 	github.com/jackc/pgservicefile v0.0.0-20240606120523-5a60cdf6a761 // indirect
-	github.com/jackc/pgx/v5 v5.7.5 // indirect
 	github.com/jackc/puddle/v2 v2.2.2 // indirect
 	github.com/jinzhu/inflection v1.0.0 // indirect
 	github.com/jinzhu/now v1.1.5 // indirect
@@ -26,5 +27,4 @@ require (
 	golang.org/x/crypto v0.38.0 // indirect
 	golang.org/x/sync v0.15.0 // indirect
 	golang.org/x/text v0.25.0 // indirect
-	gorm.io/driver/postgres v1.6.0 // indirect
 )
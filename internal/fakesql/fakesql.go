@@ -0,0 +1,243 @@
+// Package fakesql is a database/sql driver for tests. It records the statements it is sent,
+// including the begin, commit and rollback of transactions, and answers them with scripted
+// results, so repositories can be exercised through GORM without a database server.
+package fakesql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Statement is a statement the driver was sent
+type Statement struct {
+	SQL  string
+	Args []driver.Value
+}
+
+// Result answers a statement: the rows a query returns, the rows an exec affected, or an error
+type Result struct {
+	Columns      []string
+	Rows         [][]driver.Value
+	RowsAffected int64
+	Err          error
+}
+
+// rule answers the first statement starting with prefix
+type rule struct {
+	prefix string
+	result Result
+}
+
+// DB records the statements of every connection opened through it
+type DB struct {
+	mu         sync.Mutex
+	statements []Statement
+	rules      []rule
+	open       int
+}
+
+// Open returns a GORM PostgreSQL handle whose statements are recorded by the returned DB.
+// Statements run outside a transaction unless the code under test begins one.
+func Open(t testing.TB) (*gorm.DB, *DB) {
+	t.Helper()
+
+	fake := &DB{}
+	sqlDB := sql.OpenDB(connector{fake})
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		SkipDefaultTransaction: true,
+		Logger:                 logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("Failed to open fake database: %v", err)
+	}
+	return db, fake
+}
+
+// Expect answers the next statement starting with prefix with result. Statements matching no
+// expectation return no rows and affect none.
+func (db *DB) Expect(prefix string, result Result) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.rules = append(db.rules, rule{prefix: prefix, result: result})
+}
+
+// Statements returns the statements sent so far
+func (db *DB) Statements() []Statement {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return append([]Statement(nil), db.statements...)
+}
+
+// SQL returns the text of the statements sent so far
+func (db *DB) SQL() []string {
+	statements := db.Statements()
+	sql := make([]string, len(statements))
+	for i, s := range statements {
+		sql[i] = s.SQL
+	}
+	return sql
+}
+
+// OpenRows returns how many returned row sets have not been closed
+func (db *DB) OpenRows() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.open
+}
+
+// handle records a statement and returns the result of the first expectation it matches
+func (db *DB) handle(sql string, args []driver.Value) Result {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.statements = append(db.statements, Statement{SQL: sql, Args: args})
+	for i, r := range db.rules {
+		if strings.HasPrefix(sql, r.prefix) {
+			db.rules = append(db.rules[:i:i], db.rules[i+1:]...)
+			return r.result
+		}
+	}
+	return Result{}
+}
+
+// connector opens connections recording into db
+type connector struct {
+	db *DB
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{db: c.db}, nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+// fakeDriver only opens connections through a connector
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, fmt.Errorf("fakesql: use Open")
+}
+
+// conn is a connection of the fake driver
+type conn struct {
+	db *DB
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("fakesql: prepared statements are not supported")
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx records BEGIN followed by the isolation level and access mode of opts
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	begin := "BEGIN"
+	if level := sql.IsolationLevel(opts.Isolation); level != sql.LevelDefault {
+		begin += " ISOLATION LEVEL " + strings.ToUpper(level.String())
+	}
+	if opts.ReadOnly {
+		begin += " READ ONLY"
+	}
+	if result := c.db.handle(begin, nil); result.Err != nil {
+		return nil, result.Err
+	}
+	return tx{db: c.db}, nil
+}
+
+// CheckNamedValue passes every argument to the driver as it is
+func (c *conn) CheckNamedValue(*driver.NamedValue) error {
+	return nil
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	result := c.db.handle(query, values(args))
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return driver.RowsAffected(result.RowsAffected), nil
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	result := c.db.handle(query, values(args))
+	if result.Err != nil {
+		return nil, result.Err
+	}
+
+	c.db.mu.Lock()
+	c.db.open++
+	c.db.mu.Unlock()
+	return &rows{db: c.db, columns: result.Columns, values: result.Rows}, nil
+}
+
+// values returns the values of args
+func values(args []driver.NamedValue) []driver.Value {
+	list := make([]driver.Value, len(args))
+	for i, arg := range args {
+		list[i] = arg.Value
+	}
+	return list
+}
+
+// tx is a transaction of the fake driver
+type tx struct {
+	db *DB
+}
+
+func (t tx) Commit() error {
+	return t.db.handle("COMMIT", nil).Err
+}
+
+func (t tx) Rollback() error {
+	return t.db.handle("ROLLBACK", nil).Err
+}
+
+// rows returns scripted rows
+type rows struct {
+	db      *DB
+	columns []string
+	values  [][]driver.Value
+	closed  bool
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	if !r.closed {
+		r.closed = true
+		r.db.mu.Lock()
+		r.db.open--
+		r.db.mu.Unlock()
+	}
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
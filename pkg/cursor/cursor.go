@@ -0,0 +1,236 @@
+// Package cursor encodes keyset pagination positions as opaque, tamper-evident page tokens
+package cursor
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+)
+
+// Cursor is the position of a page boundary in a keyset-paginated query
+type Cursor struct {
+	// Backward asks for the rows before the position instead of after it
+	Backward bool `json:"b,omitempty"`
+	// Query is the Fingerprint of the filter and sort the position belongs to
+	Query string `json:"q"`
+	// Key holds the sort key values and ID of the boundary row, encoded by the backend
+	Key []byte `json:"k"`
+}
+
+var (
+	defaultKey     []byte
+	defaultKeyOnce sync.Once
+)
+
+// DefaultKey returns a signing key generated once per process. Tokens signed with it do
+// not survive a restart and are not accepted by other instances; configure a shared key
+// for services running more than one instance.
+func DefaultKey() []byte {
+	defaultKeyOnce.Do(func() {
+		defaultKey = make([]byte, sha256.Size)
+		if _, err := rand.Read(defaultKey); err != nil {
+			panic(fmt.Sprintf("cursor: failed to generate signing key: %v", err))
+		}
+	})
+	return defaultKey
+}
+
+// Encode returns c as a URL-safe token signed with key
+func Encode(key []byte, c Cursor) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(sign(key, payload)), nil
+}
+
+// Decode verifies a token produced by Encode with the same key and returns its cursor.
+// Malformed, tampered or foreign tokens are reported as ErrInvalidFilter.
+func Decode(key []byte, token string) (Cursor, error) {
+	encodedPayload, encodedSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return Cursor{}, fmt.Errorf("%w: malformed cursor", repoErrors.ErrInvalidFilter)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encodedPayload)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: malformed cursor", repoErrors.ErrInvalidFilter)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(encodedSignature)
+	if err != nil {
+		return Cursor{}, fmt.Errorf("%w: malformed cursor", repoErrors.ErrInvalidFilter)
+	}
+
+	if !hmac.Equal(signature, sign(key, payload)) {
+		return Cursor{}, fmt.Errorf("%w: cursor signature mismatch", repoErrors.ErrInvalidFilter)
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return Cursor{}, fmt.Errorf("%w: malformed cursor", repoErrors.ErrInvalidFilter)
+	}
+
+	return c, nil
+}
+
+// Parse decodes a page token for the query with the given fingerprint. An empty token
+// requests the first page and yields a nil cursor.
+func Parse(key []byte, token, query string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	c, err := Decode(key, token)
+	if err != nil {
+		return nil, err
+	}
+	if c.Query != query {
+		return nil, fmt.Errorf("%w: cursor belongs to a different filter or sort order", repoErrors.ErrInvalidFilter)
+	}
+
+	return &c, nil
+}
+
+// Fingerprint identifies a filter and sort specification, so a cursor is only used with the
+// query it was created for. Filters are compared by their conditions.
+func Fingerprint(filter types.Identifier, sort types.SortSpec) (string, error) {
+	conditions, err := identifier.Conditions(filter)
+	if err != nil {
+		return "", err
+	}
+
+	var b bytes.Buffer
+	for _, condition := range conditions {
+		fmt.Fprintf(&b, "%#v;", condition)
+	}
+	for i, field := range sort {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, "%s:%s:%s", field.Field, field.Direction, field.Nulls)
+	}
+
+	sum := sha256.Sum256(b.Bytes())
+	return base64.RawURLEncoding.EncodeToString(sum[:8]), nil
+}
+
+// sign returns the HMAC-SHA256 of payload under key
+func sign(key, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// Keyset prepares sort for keyset pagination. idField is appended as a final tiebreaker
+// unless sort already contains it, so every row has a unique position, and default NULLS
+// placements are made explicit: ascNulls where the backend puts nulls in ascending order,
+// the opposite in descending order.
+func Keyset(sort types.SortSpec, idField string, ascNulls types.NullsOrder) types.SortSpec {
+	keyset := make(types.SortSpec, 0, len(sort)+1)
+	hasID := false
+	for _, field := range sort {
+		if field.Field == idField {
+			hasID = true
+		}
+		if field.Nulls == types.NullsDefault {
+			field.Nulls = ascNulls
+			if field.Direction == types.SortDesc {
+				field.Nulls = flipNulls(ascNulls)
+			}
+		}
+		keyset = append(keyset, field)
+	}
+
+	if !hasID {
+		keyset = append(keyset, types.SortField{Field: idField, Direction: types.SortAsc, Nulls: ascNulls})
+	}
+
+	return keyset
+}
+
+// Reverse returns sort with every direction and NULLS placement flipped
+func Reverse(sort types.SortSpec) types.SortSpec {
+	reversed := make(types.SortSpec, len(sort))
+	for i, field := range sort {
+		field.Direction = types.SortAsc
+		if sort[i].Direction == types.SortAsc {
+			field.Direction = types.SortDesc
+		}
+		field.Nulls = flipNulls(field.Nulls)
+		reversed[i] = field
+	}
+	return reversed
+}
+
+// BuildPage turns rows fetched with a limit of limit+1 into a page with its cursors, bound to
+// the query fingerprint query. current is the cursor the rows were fetched from, or nil for
+// the first page, and keyOf encodes the sort key of a row for the cursors pointing at it.
+func BuildPage[T any](key []byte, query string, current *Cursor, rows []T, limit int, keyOf func(T) ([]byte, error)) (types.Page[T], error) {
+	backward := current != nil && current.Backward
+	hasMore := len(rows) > limit
+	if hasMore {
+		rows = rows[:limit]
+	}
+	if backward {
+		slices.Reverse(rows)
+	}
+
+	page := types.Page[T]{Items: rows}
+	if len(rows) == 0 {
+		return page, nil
+	}
+
+	hasNext, hasPrev := hasMore, current != nil
+	if backward {
+		hasNext, hasPrev = true, hasMore
+	}
+
+	if hasNext {
+		token, err := boundary(key, query, false, rows[len(rows)-1], keyOf)
+		if err != nil {
+			return page, err
+		}
+		page.NextCursor = token
+	}
+	if hasPrev {
+		token, err := boundary(key, query, true, rows[0], keyOf)
+		if err != nil {
+			return page, err
+		}
+		page.PrevCursor = token
+	}
+
+	return page, nil
+}
+
+// boundary encodes the cursor pointing before or after row
+func boundary[T any](key []byte, query string, backward bool, row T, keyOf func(T) ([]byte, error)) (string, error) {
+	rowKey, err := keyOf(row)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return Encode(key, Cursor{Backward: backward, Query: query, Key: rowKey})
+}
+
+// flipNulls swaps NULLS FIRST and NULLS LAST
+func flipNulls(nulls types.NullsOrder) types.NullsOrder {
+	switch nulls {
+	case types.NullsFirst:
+		return types.NullsLast
+	case types.NullsLast:
+		return types.NullsFirst
+	}
+	return nulls
+}
@@ -0,0 +1,168 @@
+package cursor_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/cursor"
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+)
+
+var key = []byte("test-signing-key")
+
+func TestEncodeDecode(t *testing.T) {
+	c := cursor.Cursor{Backward: true, Query: "abc", Key: []byte{1, 2, 3}}
+
+	token, err := cursor.Encode(key, c)
+	if err != nil {
+		t.Fatalf("Failed to encode cursor: %v", err)
+	}
+
+	decoded, err := cursor.Decode(key, token)
+	if err != nil {
+		t.Fatalf("Failed to decode cursor: %v", err)
+	}
+
+	if !reflect.DeepEqual(decoded, c) {
+		t.Errorf("Expected %+v, got %+v", c, decoded)
+	}
+}
+
+func TestDecode_RejectsTamperedTokens(t *testing.T) {
+	token, err := cursor.Encode(key, cursor.Cursor{Query: "abc", Key: []byte{1}})
+	if err != nil {
+		t.Fatalf("Failed to encode cursor: %v", err)
+	}
+
+	otherToken, err := cursor.Encode(key, cursor.Cursor{Query: "abc", Key: []byte{2}})
+	if err != nil {
+		t.Fatalf("Failed to encode cursor: %v", err)
+	}
+
+	payload, _, _ := strings.Cut(token, ".")
+	_, otherSignature, _ := strings.Cut(otherToken, ".")
+
+	tests := map[string]struct {
+		key   []byte
+		token string
+	}{
+		"swapped signature": {key, payload + "." + otherSignature},
+		"other key":         {[]byte("other-key"), token},
+		"not a token":       {key, "garbage"},
+		"bad encoding":      {key, "!!!." + otherSignature},
+	}
+
+	for name, tt := range tests {
+		if _, err := cursor.Decode(tt.key, tt.token); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+			t.Errorf("%s: expected ErrInvalidFilter, got %v", name, err)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	if c, err := cursor.Parse(key, "", "abc"); c != nil || err != nil {
+		t.Errorf("Expected no cursor for an empty token, got %v, %v", c, err)
+	}
+
+	token, _ := cursor.Encode(key, cursor.Cursor{Query: "abc"})
+	if _, err := cursor.Parse(key, token, "def"); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+		t.Errorf("Expected ErrInvalidFilter for a cursor of another sort, got %v", err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	sort := types.SortSpec{{Field: "name", Direction: types.SortAsc}}
+	fingerprint := func(filter types.Identifier, sort types.SortSpec) string {
+		t.Helper()
+		f, err := cursor.Fingerprint(filter, sort)
+		if err != nil {
+			t.Fatalf("Failed to fingerprint: %v", err)
+		}
+		return f
+	}
+
+	adults := fingerprint(identifier.New().GreaterOrEqual("age", 18).Equal("active", true), sort)
+	if same := fingerprint(identifier.New().Equal("active", true).GreaterOrEqual("age", 18), sort); same != adults {
+		t.Errorf("Expected equal filters to match, got %s and %s", adults, same)
+	}
+	if fingerprint(nil, sort) != fingerprint(identifier.New(), sort) {
+		t.Error("Expected a nil filter to match an empty one")
+	}
+
+	grouped := identifier.New()
+	grouped.GreaterOrEqual("age", 18).Equal("active", true)
+	grouped.Or(identifier.New().Equal("role", "admin"))
+
+	others := map[string]string{
+		"other value":    fingerprint(identifier.New().GreaterOrEqual("age", 21).Equal("active", true), sort),
+		"other operator": fingerprint(identifier.New().GreaterThan("age", 18).Equal("active", true), sort),
+		"other group":    fingerprint(grouped, sort),
+		"other sort":     fingerprint(identifier.New().GreaterOrEqual("age", 18).Equal("active", true), cursor.Reverse(sort)),
+	}
+	for name, other := range others {
+		if other == adults {
+			t.Errorf("%s: expected a different fingerprint", name)
+		}
+	}
+}
+
+func TestKeyset(t *testing.T) {
+	sort := types.SortSpec{
+		{Field: "name", Direction: types.SortAsc},
+		{Field: "age", Direction: types.SortDesc},
+	}
+
+	expected := types.SortSpec{
+		{Field: "name", Direction: types.SortAsc, Nulls: types.NullsLast},
+		{Field: "age", Direction: types.SortDesc, Nulls: types.NullsFirst},
+		{Field: "id", Direction: types.SortAsc, Nulls: types.NullsLast},
+	}
+	if keyset := cursor.Keyset(sort, "id", types.NullsLast); !reflect.DeepEqual(keyset, expected) {
+		t.Errorf("Expected %v, got %v", expected, keyset)
+	}
+
+	withID := types.SortSpec{{Field: "id", Direction: types.SortDesc, Nulls: types.NullsFirst}}
+	if keyset := cursor.Keyset(withID, "id", types.NullsLast); !reflect.DeepEqual(keyset, withID) {
+		t.Errorf("Expected the ID not to be added twice, got %v", keyset)
+	}
+
+	reversed := types.SortSpec{
+		{Field: "name", Direction: types.SortDesc, Nulls: types.NullsFirst},
+		{Field: "age", Direction: types.SortAsc, Nulls: types.NullsLast},
+		{Field: "id", Direction: types.SortDesc, Nulls: types.NullsFirst},
+	}
+	if got := cursor.Reverse(expected); !reflect.DeepEqual(got, reversed) {
+		t.Errorf("Expected %v, got %v", reversed, got)
+	}
+}
+
+func TestBuildPage(t *testing.T) {
+	keyOf := func(row int) ([]byte, error) { return []byte{byte(row)}, nil }
+
+	first, err := cursor.BuildPage(key, "s", nil, []int{1, 2, 3}, 2, keyOf)
+	if err != nil {
+		t.Fatalf("Failed to build page: %v", err)
+	}
+	if !reflect.DeepEqual(first.Items, []int{1, 2}) || first.NextCursor == "" || first.PrevCursor != "" {
+		t.Fatalf("Unexpected first page: %+v", first)
+	}
+
+	next, _ := cursor.Parse(key, first.NextCursor, "s")
+	if next.Backward || !reflect.DeepEqual(next.Key, []byte{2}) {
+		t.Errorf("Expected a forward cursor after row 2, got %+v", next)
+	}
+
+	// rows fetched backwards arrive in reverse order
+	previous := &cursor.Cursor{Backward: true, Query: "s"}
+	page, err := cursor.BuildPage(key, "s", previous, []int{4, 3}, 2, keyOf)
+	if err != nil {
+		t.Fatalf("Failed to build page: %v", err)
+	}
+	if !reflect.DeepEqual(page.Items, []int{3, 4}) || page.NextCursor == "" || page.PrevCursor != "" {
+		t.Errorf("Unexpected previous page: %+v", page)
+	}
+}
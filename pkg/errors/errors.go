@@ -0,0 +1,51 @@
+// Package errors defines the backend-independent errors returned by the base repositories.
+// Both implementations translate driver errors into these, so callers can tell failures
+// apart with errors.Is without importing either driver.
+package errors
+
+import "errors"
+
+var (
+	// ErrNotFound is returned when no entity matches the query
+	ErrNotFound = errors.New("entity not found")
+	// ErrDuplicateKey is returned when a write violates a unique index or constraint
+	ErrDuplicateKey = errors.New("duplicate key")
+	// ErrConflict is returned when a write lost against a concurrent modification of the same data
+	ErrConflict = errors.New("conflicting concurrent modification")
+	// ErrInvalidFilter is returned when a filter, sort or field cannot be used for the query
+	ErrInvalidFilter = errors.New("invalid filter")
+	// ErrInvalidUpdate is returned when a change cannot be applied to the field it names
+	ErrInvalidUpdate = errors.New("invalid update")
+	// ErrTxAborted is returned when the database aborted the transaction
+	ErrTxAborted = errors.New("transaction aborted")
+)
+
+// Error ties a driver error to one of the repository errors. errors.Is matches
+// the repository error, while Unwrap returns the original driver error.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+// Wrap returns an error of the given kind caused by err
+func Wrap(kind, err error) error {
+	return &Error{Kind: kind, Err: err}
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	if e.Err == nil {
+		return e.Kind.Error()
+	}
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap returns the original driver error
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the kind of e
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
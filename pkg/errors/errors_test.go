@@ -0,0 +1,46 @@
+package errors_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+)
+
+func TestWrap(t *testing.T) {
+	driverErr := errors.New("E11000 duplicate key error")
+
+	err := repoErrors.Wrap(repoErrors.ErrDuplicateKey, driverErr)
+
+	if !errors.Is(err, repoErrors.ErrDuplicateKey) {
+		t.Error("Expected the error to match ErrDuplicateKey")
+	}
+
+	if errors.Is(err, repoErrors.ErrNotFound) {
+		t.Error("Expected the error not to match ErrNotFound")
+	}
+
+	if errors.Unwrap(err) != driverErr {
+		t.Errorf("Expected Unwrap to return the driver error, got %v", errors.Unwrap(err))
+	}
+
+	expected := "duplicate key: E11000 duplicate key error"
+	if err.Error() != expected {
+		t.Errorf("Expected %q, got %q", expected, err.Error())
+	}
+}
+
+func TestWrap_InsideOperationError(t *testing.T) {
+	driverErr := errors.New("record not found")
+
+	err := fmt.Errorf("failed to find by id: %w", repoErrors.Wrap(repoErrors.ErrNotFound, driverErr))
+
+	if !errors.Is(err, repoErrors.ErrNotFound) {
+		t.Error("Expected the error to match ErrNotFound")
+	}
+
+	if !errors.Is(err, driverErr) {
+		t.Error("Expected the driver error to stay in the chain")
+	}
+}
@@ -1,49 +1,123 @@
 package factory
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/arash-mosavi/go-base-repository/pkg/interfaces"
 	"github.com/arash-mosavi/go-base-repository/pkg/mongo"
 	"github.com/arash-mosavi/go-base-repository/pkg/postgres"
+	"github.com/arash-mosavi/go-base-repository/pkg/retry"
 	"github.com/arash-mosavi/go-base-repository/pkg/types"
 	mongoFactory "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/mongodb"
 	postgresFactory "github.com/arash-mosavi/postgrs-unit-of-work-system/pkg/postgres"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	postgresDriver "gorm.io/driver/postgres"
+	"gorm.io/gorm"
 )
 
 // MongoConfig wraps MongoDB configuration
 type MongoConfig struct {
 	*mongoFactory.Config
+	// RetryPolicy controls how WithTransaction retries transient transaction failures
+	RetryPolicy retry.Policy
+	// CursorKey signs FindPage cursors; empty uses a random per-process key
+	CursorKey []byte
+	// BatchSize is how many rows Stream fetches per round trip; zero uses the default
+	BatchSize int
 }
 
 // PostgresConfig wraps PostgreSQL configuration
 type PostgresConfig struct {
 	*postgresFactory.Config
+	// RetryPolicy controls how WithTransaction retries serialization failures and deadlocks
+	RetryPolicy retry.Policy
+	// CursorKey signs FindPage cursors; empty uses a random per-process key
+	CursorKey []byte
+	// BatchSize is how many rows Stream fetches per round trip; zero uses the default
+	BatchSize int
 }
 
 // NewMongoConfig creates a new MongoDB configuration
 func NewMongoConfig() *MongoConfig {
 	return &MongoConfig{
-		Config: mongoFactory.NewConfig(),
+		Config:      mongoFactory.NewConfig(),
+		RetryPolicy: retry.DefaultPolicy(),
 	}
 }
 
 // NewPostgresConfig creates a new PostgreSQL configuration
 func NewPostgresConfig() *PostgresConfig {
 	return &PostgresConfig{
-		Config: postgresFactory.NewConfig(),
+		Config:      postgresFactory.NewConfig(),
+		RetryPolicy: retry.DefaultPolicy(),
 	}
 }
 
+// NewMongoDatabase connects to MongoDB and returns the configured database.
+// Repositories created from the same database share its client and can take part in the same transaction.
+func NewMongoDatabase(config *MongoConfig) (*mongoDriver.Database, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	clientOptions := options.Client().ApplyURI(config.ConnectionString())
+	clientOptions.SetMaxPoolSize(config.MaxPoolSize)
+	clientOptions.SetMinPoolSize(config.MinPoolSize)
+	clientOptions.SetMaxConnIdleTime(config.MaxIdleTime)
+
+	client, err := mongoDriver.Connect(ctx, clientOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+	}
+
+	if err := client.Ping(ctx, nil); err != nil {
+		return nil, fmt.Errorf("failed to ping MongoDB: %w", err)
+	}
+
+	return client.Database(config.Database), nil
+}
+
+// NewPostgresDB opens a GORM connection to PostgreSQL.
+// Repositories created from the same handle can take part in the same transaction.
+func NewPostgresDB(config *PostgresConfig) (*gorm.DB, error) {
+	dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s",
+		config.Host, config.Port, config.User, config.Password, config.Database)
+
+	db, err := gorm.Open(postgresDriver.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to PostgreSQL: %w", err)
+	}
+
+	return db, nil
+}
+
 // NewMongoBaseRepository creates a new MongoDB base repository
 func NewMongoBaseRepository[T types.MongoEntity](config *MongoConfig) (interfaces.MongoBaseRepository[T], error) {
-	factory, err := mongoFactory.NewFactory[T](config.Config)
+	database, err := NewMongoDatabase(config)
 	if err != nil {
 		return nil, err
 	}
-	return mongo.NewBaseRepository[T](factory), nil
+	return mongo.NewBaseRepository[T](database,
+		mongo.WithRetryPolicy(config.RetryPolicy),
+		mongo.WithCursorKey(config.CursorKey),
+		mongo.WithBatchSize(config.BatchSize),
+	), nil
 }
 
 // NewPostgresBaseRepository creates a new PostgreSQL base repository
 func NewPostgresBaseRepository[T types.PostgresEntity](config *PostgresConfig) (interfaces.PostgresBaseRepository[T], error) {
-	factory := postgresFactory.NewUnitOfWorkFactory[T](config.Config)
-	return postgres.NewBaseRepository[T](factory), nil
+	db, err := NewPostgresDB(config)
+	if err != nil {
+		return nil, err
+	}
+	return postgres.NewBaseRepository[T](db,
+		postgres.WithRetryPolicy(config.RetryPolicy),
+		postgres.WithCursorKey(config.CursorKey),
+		postgres.WithBatchSize(config.BatchSize),
+	), nil
 }
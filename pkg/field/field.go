@@ -0,0 +1,146 @@
+// Package field provides typed references to entity fields. The fieldgen command generates one
+// per field of an entity, named by its bson key or column, so conditions are checked by the
+// compiler instead of failing at query time:
+//
+//	filter := identifier.New().And(
+//		UserFields.Email.Eq("a@b.c"),
+//		UserFields.Age.Gt(18),
+//	)
+//
+// Every condition method returns a new backend-neutral identifier.
+package field
+
+import (
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+)
+
+// Field is a field of an entity holding values of type V
+type Field[V any] struct {
+	name string
+}
+
+// New returns a reference to the field stored as name
+func New[V any](name string) Field[V] {
+	return Field[V]{name: name}
+}
+
+// Name returns the stored name of the field: its bson key or column
+func (f Field[V]) Name() string {
+	return f.name
+}
+
+// Eq matches values equal to value
+func (f Field[V]) Eq(value V) types.Identifier {
+	return identifier.New().Equal(f.name, value)
+}
+
+// Ne matches values not equal to value
+func (f Field[V]) Ne(value V) types.Identifier {
+	return identifier.New().NotEqual(f.name, value)
+}
+
+// Gt matches values greater than value
+func (f Field[V]) Gt(value V) types.Identifier {
+	return identifier.New().GreaterThan(f.name, value)
+}
+
+// Gte matches values greater than or equal to value
+func (f Field[V]) Gte(value V) types.Identifier {
+	return identifier.New().GreaterOrEqual(f.name, value)
+}
+
+// Lt matches values less than value
+func (f Field[V]) Lt(value V) types.Identifier {
+	return identifier.New().LessThan(f.name, value)
+}
+
+// Lte matches values less than or equal to value
+func (f Field[V]) Lte(value V) types.Identifier {
+	return identifier.New().LessOrEqual(f.name, value)
+}
+
+// Between matches values between min and max, inclusive
+func (f Field[V]) Between(min, max V) types.Identifier {
+	return identifier.New().Between(f.name, min, max)
+}
+
+// In matches values equal to any of values
+func (f Field[V]) In(values ...V) types.Identifier {
+	return identifier.New().In(f.name, list(values))
+}
+
+// NotIn matches values equal to none of values
+func (f Field[V]) NotIn(values ...V) types.Identifier {
+	return identifier.New().NotIn(f.name, list(values))
+}
+
+// IsNull matches a missing or null field
+func (f Field[V]) IsNull() types.Identifier {
+	return identifier.New().IsNull(f.name)
+}
+
+// IsNotNull matches a present, non-null field
+func (f Field[V]) IsNotNull() types.Identifier {
+	return identifier.New().IsNotNull(f.name)
+}
+
+// Exists matches documents that have, or lack, the field
+func (f Field[V]) Exists(exists bool) types.Identifier {
+	return identifier.New().Exists(f.name, exists)
+}
+
+// Asc sorts by the field in ascending order
+func (f Field[V]) Asc() types.SortField {
+	return types.SortField{Field: f.name, Direction: types.SortAsc}
+}
+
+// Desc sorts by the field in descending order
+func (f Field[V]) Desc() types.SortField {
+	return types.SortField{Field: f.name, Direction: types.SortDesc}
+}
+
+// Text is a field holding strings, which can also be matched by pattern
+type Text[V ~string] struct {
+	Field[V]
+}
+
+// NewText returns a reference to the text field stored as name
+func NewText[V ~string](name string) Text[V] {
+	return Text[V]{Field: New[V](name)}
+}
+
+// Like matches values against an SQL LIKE pattern, where % matches any run of characters and _
+// a single one, case-sensitively on both backends
+func (f Text[V]) Like(pattern string) types.Identifier {
+	return identifier.New().Like(f.name, pattern)
+}
+
+// ILike matches values against an SQL LIKE pattern, ignoring case
+func (f Text[V]) ILike(pattern string) types.Identifier {
+	return identifier.New().ILike(f.name, pattern)
+}
+
+// StartsWith matches values beginning with prefix, taken literally
+func (f Text[V]) StartsWith(prefix string) types.Identifier {
+	return identifier.New().StartsWith(f.name, prefix)
+}
+
+// EndsWith matches values ending with suffix, taken literally
+func (f Text[V]) EndsWith(suffix string) types.Identifier {
+	return identifier.New().EndsWith(f.name, suffix)
+}
+
+// Regex matches values against a regular expression; options may combine "i", "m", "s" and "x"
+func (f Text[V]) Regex(pattern, options string) types.Identifier {
+	return identifier.New().Regex(f.name, pattern, options)
+}
+
+// list converts values to the list the identifier operators take
+func list[V any](values []V) []interface{} {
+	items := make([]interface{}, len(values))
+	for i, value := range values {
+		items[i] = value
+	}
+	return items
+}
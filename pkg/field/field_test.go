@@ -0,0 +1,104 @@
+package field_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/field"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type status string
+
+var userFields = struct {
+	Email     field.Text[string]
+	Status    field.Text[status]
+	Age       field.Field[int]
+	CreatedAt field.Field[time.Time]
+}{
+	Email:     field.NewText[string]("email"),
+	Status:    field.NewText[status]("status"),
+	Age:       field.New[int]("age"),
+	CreatedAt: field.New[time.Time]("createdAt"),
+}
+
+func TestField_Conditions(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		got      types.Identifier
+		expected types.Identifier
+	}{
+		{userFields.Email.Eq("a@b.c"), identifier.New().Equal("email", "a@b.c")},
+		{userFields.Email.Ne("a@b.c"), identifier.New().NotEqual("email", "a@b.c")},
+		{userFields.Age.Gt(18), identifier.New().GreaterThan("age", 18)},
+		{userFields.Age.Gte(18), identifier.New().GreaterOrEqual("age", 18)},
+		{userFields.Age.Lt(65), identifier.New().LessThan("age", 65)},
+		{userFields.Age.Lte(65), identifier.New().LessOrEqual("age", 65)},
+		{userFields.Age.Between(18, 65), identifier.New().Between("age", 18, 65)},
+		{userFields.Status.In("active", "pending"), identifier.New().In("status", []interface{}{status("active"), status("pending")})},
+		{userFields.Status.NotIn("banned"), identifier.New().NotIn("status", []interface{}{status("banned")})},
+		{userFields.CreatedAt.Gte(since), identifier.New().GreaterOrEqual("createdAt", since)},
+		{userFields.CreatedAt.IsNull(), identifier.New().IsNull("createdAt")},
+		{userFields.CreatedAt.IsNotNull(), identifier.New().IsNotNull("createdAt")},
+		{userFields.CreatedAt.Exists(false), identifier.New().Exists("createdAt", false)},
+		{userFields.Email.Like("%@b.c"), identifier.New().Like("email", "%@b.c")},
+		{userFields.Email.ILike("%@B.C"), identifier.New().ILike("email", "%@B.C")},
+		{userFields.Email.StartsWith("a"), identifier.New().StartsWith("email", "a")},
+		{userFields.Email.EndsWith(".c"), identifier.New().EndsWith("email", ".c")},
+		{userFields.Email.Regex("^a", "i"), identifier.New().Regex("email", "^a", "i")},
+	}
+
+	for _, tt := range tests {
+		got, err := identifier.Conditions(tt.got)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		expected, err := identifier.Conditions(tt.expected)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %v, got %v", expected, got)
+		}
+	}
+}
+
+func TestField_Combined(t *testing.T) {
+	filter := identifier.New()
+	filter.And(userFields.Email.Eq("a@b.c"), userFields.Age.Gt(18))
+
+	expected := identifier.New()
+	expected.And(identifier.New().Equal("email", "a@b.c"), identifier.New().GreaterThan("age", 18))
+
+	if !reflect.DeepEqual(filter.ToBSON(), expected.ToBSON()) {
+		t.Errorf("Expected %v, got %v", expected.ToBSON(), filter.ToBSON())
+	}
+}
+
+func TestText_LikeIsAPattern(t *testing.T) {
+	got := userFields.Email.Like("a.b_%").ToBSON()
+
+	expected := map[string]interface{}{"email": bson.M{"$regex": `^a\.b..*$`}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestField_Sort(t *testing.T) {
+	sort := types.SortSpec{userFields.CreatedAt.Desc(), userFields.Email.Asc()}
+
+	expected := types.SortSpec{
+		{Field: "createdAt", Direction: types.SortDesc},
+		{Field: "email", Direction: types.SortAsc},
+	}
+	if !reflect.DeepEqual(sort, expected) {
+		t.Errorf("Expected %v, got %v", expected, sort)
+	}
+	if name := userFields.Age.Name(); name != "age" {
+		t.Errorf("Expected age, got %s", name)
+	}
+}
@@ -0,0 +1,302 @@
+package identifier
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SchemaVersion is the version of the JSON encoding of identifiers. Decoding rejects any other version.
+const SchemaVersion = 1
+
+// AST is the serializable form of an identifier: its conditions, with group operands nested
+type AST struct {
+	Version    int         `json:"version"`
+	Conditions []Condition `json:"conditions"`
+}
+
+// AST returns the conditions of the identifier as a versioned tree
+func (u *UnifiedIdentifier) AST() (AST, error) {
+	conditions, err := Conditions(u)
+	if err != nil {
+		return AST{}, err
+	}
+	if conditions == nil {
+		conditions = []Condition{}
+	}
+	return AST{Version: SchemaVersion, Conditions: conditions}, nil
+}
+
+// FromAST validates ast and rebuilds it as a backend-neutral identifier. Unsupported versions,
+// unknown operators and values their operator cannot use are reported as ErrInvalidFilter.
+func FromAST(ast AST) (*UnifiedIdentifier, error) {
+	if ast.Version != SchemaVersion {
+		return nil, fmt.Errorf("%w: unsupported identifier schema version %d", repoErrors.ErrInvalidFilter, ast.Version)
+	}
+
+	u := New()
+	if err := u.addAll(ast.Conditions); err != nil {
+		return nil, err
+	}
+	if _, err := Conditions(u); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// addAll adds decoded conditions, rebuilding groups from their operands
+func (u *UnifiedIdentifier) addAll(conditions []Condition) error {
+	for _, c := range conditions {
+		if !c.IsGroup() {
+			if !knownOperators[c.Operator] {
+				return fmt.Errorf("%w: unknown operator %q", repoErrors.ErrInvalidFilter, c.Operator)
+			}
+			if len(c.Operands) > 0 {
+				return fmt.Errorf("%w: %s on field %q cannot have operands", repoErrors.ErrInvalidFilter, c.Operator, c.Field)
+			}
+			u.add(c.Field, c.Operator, c.Value)
+			continue
+		}
+
+		if c.Field != "" {
+			return fmt.Errorf("%w: %s group cannot name a field", repoErrors.ErrInvalidFilter, c.Operator)
+		}
+		if c.Operator == OpNot && len(c.Operands) != 1 {
+			return fmt.Errorf("%w: NOT group needs exactly one operand", repoErrors.ErrInvalidFilter)
+		}
+
+		g := group{operator: c.Operator}
+		for _, operand := range c.Operands {
+			member := New()
+			if err := member.addAll(operand); err != nil {
+				return err
+			}
+			g.operands = append(g.operands, member)
+		}
+		u.groups = append(u.groups, g)
+	}
+
+	return nil
+}
+
+// MarshalJSON encodes the identifier as its AST
+func (u *UnifiedIdentifier) MarshalJSON() ([]byte, error) {
+	ast, err := u.AST()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(ast)
+}
+
+// UnmarshalJSON decodes an AST produced by MarshalJSON. The identifier becomes backend-neutral,
+// whichever backend it wrapped before.
+func (u *UnifiedIdentifier) UnmarshalJSON(data []byte) error {
+	var ast AST
+	if err := decodeStrict(data, &ast); err != nil {
+		return err
+	}
+
+	decoded, err := FromAST(ast)
+	if err != nil {
+		return err
+	}
+
+	*u = *decoded
+	return nil
+}
+
+// conditionJSON is the JSON form of a Condition
+type conditionJSON struct {
+	Field    string        `json:"field,omitempty"`
+	Operator Operator      `json:"op"`
+	Value    *typedValue   `json:"value,omitempty"`
+	Operands [][]Condition `json:"operands,omitempty"`
+}
+
+// MarshalJSON encodes the condition with its value tagged by type, so it decodes verbatim
+func (c Condition) MarshalJSON() ([]byte, error) {
+	encoded := conditionJSON{Field: c.Field, Operator: c.Operator, Operands: c.Operands}
+	if !c.IsGroup() {
+		value, err := encodeValue(c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode value of field %q: %w", c.Field, err)
+		}
+		encoded.Value = &value
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON decodes a condition encoded by MarshalJSON
+func (c *Condition) UnmarshalJSON(data []byte) error {
+	var decoded conditionJSON
+	if err := decodeStrict(data, &decoded); err != nil {
+		return err
+	}
+
+	*c = Condition{Field: decoded.Field, Operator: decoded.Operator, Operands: decoded.Operands}
+	if c.IsGroup() {
+		return nil
+	}
+
+	if decoded.Value == nil {
+		return fmt.Errorf("%w: %s on field %q has no value", repoErrors.ErrInvalidFilter, c.Operator, c.Field)
+	}
+	value, err := decodeValue(*decoded.Value)
+	if err != nil {
+		return fmt.Errorf("%w: field %q: %v", repoErrors.ErrInvalidFilter, c.Field, err)
+	}
+	c.Value = value
+
+	return nil
+}
+
+// typedValue is the JSON form of a condition value
+type typedValue struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Value type tags that do not name a Go kind
+const (
+	typeNull     = "null"
+	typeTime     = "time"
+	typeObjectID = "objectId"
+	typeRegex    = "regex"
+	typeList     = "list"
+)
+
+// scalarTypes maps the type tags of scalar values to the Go type they decode to
+var scalarTypes = map[string]reflect.Type{}
+
+// knownOperators holds the operators a decoded field condition may use
+var knownOperators = map[Operator]bool{OpEqual: true}
+
+func init() {
+	for _, op := range keyOperators {
+		knownOperators[op] = true
+	}
+	for _, v := range []interface{}{
+		false, "", int(0), int8(0), int16(0), int32(0), int64(0),
+		uint(0), uint8(0), uint16(0), uint32(0), uint64(0), float32(0), float64(0),
+	} {
+		t := reflect.TypeOf(v)
+		scalarTypes[t.Kind().String()] = t
+	}
+}
+
+// encodeValue tags v with its type. Named types are encoded as their underlying kind.
+func encodeValue(v interface{}) (typedValue, error) {
+	switch x := v.(type) {
+	case nil:
+		return typedValue{Type: typeNull}, nil
+	case time.Time:
+		return marshalTyped(typeTime, x.Format(time.RFC3339Nano))
+	case primitive.ObjectID:
+		return marshalTyped(typeObjectID, x.Hex())
+	case RegexPattern:
+		return marshalTyped(typeRegex, map[string]string{"pattern": x.Pattern, "options": x.Options})
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]typedValue, rv.Len())
+		for i := range items {
+			item, err := encodeValue(rv.Index(i).Interface())
+			if err != nil {
+				return typedValue{}, err
+			}
+			items[i] = item
+		}
+		return marshalTyped(typeList, items)
+	}
+
+	t, ok := scalarTypes[rv.Kind().String()]
+	if !ok {
+		return typedValue{}, fmt.Errorf("unsupported value type %T", v)
+	}
+	return marshalTyped(rv.Kind().String(), rv.Convert(t).Interface())
+}
+
+// marshalTyped encodes value under the type tag name
+func marshalTyped(name string, value interface{}) (typedValue, error) {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return typedValue{}, err
+	}
+	return typedValue{Type: name, Value: raw}, nil
+}
+
+// decodeValue restores a value encoded by encodeValue
+func decodeValue(tv typedValue) (interface{}, error) {
+	switch tv.Type {
+	case typeNull:
+		return nil, nil
+	case typeTime:
+		var s string
+		if err := json.Unmarshal(tv.Value, &s); err != nil {
+			return nil, fmt.Errorf("malformed time: %v", err)
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	case typeObjectID:
+		var s string
+		if err := json.Unmarshal(tv.Value, &s); err != nil {
+			return nil, fmt.Errorf("malformed objectId: %v", err)
+		}
+		return primitive.ObjectIDFromHex(s)
+	case typeRegex:
+		var regex struct {
+			Pattern string `json:"pattern"`
+			Options string `json:"options"`
+		}
+		if err := decodeStrict(tv.Value, &regex); err != nil {
+			return nil, fmt.Errorf("malformed regex: %v", err)
+		}
+		return RegexPattern{Pattern: regex.Pattern, Options: regex.Options}, nil
+	case typeList:
+		var items []typedValue
+		if err := json.Unmarshal(tv.Value, &items); err != nil {
+			return nil, fmt.Errorf("malformed list: %v", err)
+		}
+		list := make([]interface{}, len(items))
+		for i, item := range items {
+			value, err := decodeValue(item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = value
+		}
+		return list, nil
+	}
+
+	t, ok := scalarTypes[tv.Type]
+	if !ok {
+		return nil, fmt.Errorf("unknown value type %q", tv.Type)
+	}
+	ptr := reflect.New(t)
+	if err := json.Unmarshal(tv.Value, ptr.Interface()); err != nil {
+		return nil, fmt.Errorf("malformed %s: %v", tv.Type, err)
+	}
+	return ptr.Elem().Interface(), nil
+}
+
+// decodeStrict unmarshals data into v, rejecting unknown fields. Syntax errors are
+// reported as ErrInvalidFilter.
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		if errors.Is(err, repoErrors.ErrInvalidFilter) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", repoErrors.ErrInvalidFilter, err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package identifier_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUnifiedIdentifier_JSONRoundTrip(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+	since := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+
+	filter := identifier.New()
+	filter.Equal("ownerId", ownerID).
+		GreaterOrEqual("createdAt", since).
+		Between("score", 1.5, int64(10)).
+		In("status", []interface{}{"active", "pending"}).
+		Regex("name", "^jo", "i").
+		IsNull("deletedAt").
+		Equal("manager", nil)
+	filter.Or(
+		identifier.New().Equal("role", "admin"),
+		identifier.New().Equal("age", 42).Equal("verified", true),
+	)
+	filter.Not(identifier.New().StartsWith("email", "test"))
+
+	data, err := json.Marshal(filter)
+	if err != nil {
+		t.Fatalf("Failed to marshal identifier: %v", err)
+	}
+
+	var decoded identifier.UnifiedIdentifier
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Failed to unmarshal identifier: %v", err)
+	}
+
+	expected, err := identifier.Conditions(filter)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	got, err := identifier.Conditions(&decoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	if !reflect.DeepEqual(decoded.ToBSON(), filter.ToBSON()) {
+		t.Errorf("Expected BSON %v, got %v", filter.ToBSON(), decoded.ToBSON())
+	}
+}
+
+func TestUnifiedIdentifier_JSONFromBackendIdentifier(t *testing.T) {
+	data, err := json.Marshal(identifier.NewPostgresIdentifier().Equal("name", "test").GreaterThan("age", 18))
+	if err != nil {
+		t.Fatalf("Failed to marshal identifier: %v", err)
+	}
+
+	expected := `{"version":1,"conditions":[` +
+		`{"field":"age","op":"\u003e","value":{"type":"int","value":18}},` +
+		`{"field":"name","op":"=","value":{"type":"string","value":"test"}}]}`
+	if string(data) != expected {
+		t.Errorf("Expected %s, got %s", expected, data)
+	}
+}
+
+func TestUnifiedIdentifier_JSONValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unsupported version", `{"version":2,"conditions":[]}`},
+		{"unknown field", `{"version":1,"conditions":[],"limit":10}`},
+		{"unknown operator", `{"version":1,"conditions":[{"field":"age","op":"~=","value":{"type":"int","value":1}}]}`},
+		{"missing value", `{"version":1,"conditions":[{"field":"age","op":">"}]}`},
+		{"unknown value type", `{"version":1,"conditions":[{"field":"age","op":"=","value":{"type":"decimal","value":1}}]}`},
+		{"mistyped value", `{"version":1,"conditions":[{"field":"age","op":"=","value":{"type":"int8","value":300}}]}`},
+		{"missing field", `{"version":1,"conditions":[{"op":"=","value":{"type":"int","value":1}}]}`},
+		{"IN without list", `{"version":1,"conditions":[{"field":"status","op":"IN","value":{"type":"string","value":"a"}}]}`},
+		{"empty group", `{"version":1,"conditions":[{"op":"OR"}]}`},
+		{"NOT with two operands", `{"version":1,"conditions":[{"op":"NOT","operands":[` +
+			`[{"field":"a","op":"=","value":{"type":"int","value":1}}],` +
+			`[{"field":"b","op":"=","value":{"type":"int","value":2}}]]}]}`},
+	}
+
+	for _, tt := range tests {
+		var decoded identifier.UnifiedIdentifier
+		if err := json.Unmarshal([]byte(tt.data), &decoded); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+			t.Errorf("%s: expected ErrInvalidFilter, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestUnifiedIdentifier_JSONUnsupportedValue(t *testing.T) {
+	filter := identifier.New().Equal("meta", map[string]string{"a": "b"})
+
+	if _, err := json.Marshal(filter); err == nil {
+		t.Error("Expected an error for an unsupported value type")
+	}
+}
@@ -0,0 +1,148 @@
+package identifier
+
+import (
+	"regexp"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// groupOperators maps group operators to the MongoDB logical query operators rendering them
+var groupOperators = map[Operator]string{
+	OpAnd: "$and",
+	OpOr:  "$or",
+	OpNot: "$nor",
+}
+
+// Document renders ANDed conditions as a MongoDB filter document. Groups become $and, $or and
+// $nor clauses; several groups are ANDed through a surrounding $and.
+func Document(conditions []Condition) bson.M {
+	query := bson.M{}
+	var groups bson.A
+	for _, c := range conditions {
+		if c.IsGroup() {
+			groups = append(groups, c.BSON())
+			continue
+		}
+		MergeCondition(query, c.Field, c.BSON())
+	}
+
+	switch len(groups) {
+	case 0:
+	case 1:
+		for op, value := range groups[0].(bson.M) {
+			query[op] = value
+		}
+	default:
+		query["$and"] = groups
+	}
+
+	return query
+}
+
+// BSON renders the condition for MongoDB: the value matched against its field, or the logical
+// query operator document of a group
+func (c Condition) BSON() interface{} {
+	switch c.Operator {
+	case OpAnd, OpOr, OpNot:
+		operands := make(bson.A, 0, len(c.Operands))
+		for _, operand := range c.Operands {
+			operands = append(operands, Document(operand))
+		}
+		return bson.M{groupOperators[c.Operator]: operands}
+	case OpNotEqual:
+		return bson.M{"$ne": c.Value}
+	case OpGreaterThan:
+		return bson.M{"$gt": c.Value}
+	case OpGreaterOrEqual:
+		return bson.M{"$gte": c.Value}
+	case OpLessThan:
+		return bson.M{"$lt": c.Value}
+	case OpLessOrEqual:
+		return bson.M{"$lte": c.Value}
+	case OpIn:
+		return bson.M{"$in": c.Value}
+	case OpNotIn:
+		return bson.M{"$nin": c.Value}
+	case OpLike:
+		return bson.M{"$regex": likeToRegex(c.Value.(string))}
+	case OpILike:
+		return bson.M{"$regex": likeToRegex(c.Value.(string)), "$options": "i"}
+	case OpStartsWith:
+		return bson.M{"$regex": "^" + regexp.QuoteMeta(c.Value.(string))}
+	case OpEndsWith:
+		return bson.M{"$regex": regexp.QuoteMeta(c.Value.(string)) + "$"}
+	case OpRegex:
+		regex := c.Value.(RegexPattern)
+		if regex.Options == "" {
+			return bson.M{"$regex": regex.Pattern}
+		}
+		return bson.M{"$regex": regex.Pattern, "$options": regex.Options}
+	case OpBetween:
+		bounds := c.Value.([]interface{})
+		return bson.M{"$gte": bounds[0], "$lte": bounds[1]}
+	case OpIsNull:
+		return nil
+	case OpIsNotNull:
+		return bson.M{"$ne": nil}
+	case OpExists:
+		return bson.M{"$exists": c.Value}
+	default:
+		return c.Value
+	}
+}
+
+// MergeCondition sets field to expr in query, merging it with an existing condition on the same
+// field so that e.g. a lower and an upper bound both apply
+func MergeCondition(query bson.M, field string, expr interface{}) {
+	existing, ok := query[field]
+	if !ok {
+		query[field] = expr
+		return
+	}
+
+	merged := operatorDocument(existing)
+	for op, value := range operatorDocument(expr) {
+		merged[op] = value
+	}
+	query[field] = merged
+}
+
+// operatorDocument returns expr as an operator document, turning a plain value into $eq
+func operatorDocument(expr interface{}) bson.M {
+	if doc, ok := expr.(bson.M); ok {
+		merged := bson.M{}
+		for op, value := range doc {
+			merged[op] = value
+		}
+		return merged
+	}
+	return bson.M{"$eq": expr}
+}
+
+// likeToRegex translates a SQL LIKE pattern into an anchored regular expression: % matches any
+// run of characters, _ a single character and a backslash escapes the character after it
+func likeToRegex(pattern string) string {
+	var b strings.Builder
+	b.WriteByte('^')
+
+	escaped := false
+	for _, r := range pattern {
+		switch {
+		case escaped:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '%':
+			b.WriteString(".*")
+		case r == '_':
+			b.WriteByte('.')
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	b.WriteByte('$')
+	return b.String()
+}
@@ -0,0 +1,221 @@
+package identifier
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+)
+
+// Operator is the comparison a Condition applies to its field
+type Operator string
+
+const (
+	OpEqual       Operator = "="
+	OpGreaterThan Operator = ">"
+	OpLessThan    Operator = "<"
+	OpIn          Operator = "IN"
+	OpLike        Operator = "LIKE"
+	OpBetween     Operator = "BETWEEN"
+	OpIsNull      Operator = "IS NULL"
+	OpIsNotNull   Operator = "IS NOT NULL"
+
+	OpNotEqual       Operator = "!="
+	OpGreaterOrEqual Operator = ">="
+	OpLessOrEqual    Operator = "<="
+	OpNotIn          Operator = "NOT IN"
+	OpExists         Operator = "EXISTS"
+	OpStartsWith     Operator = "STARTS WITH"
+	OpEndsWith       Operator = "ENDS WITH"
+	OpILike          Operator = "ILIKE"
+	OpRegex          Operator = "REGEX"
+
+	// OpAnd, OpOr and OpNot combine the conditions of other identifiers in a group
+	OpAnd Operator = "AND"
+	OpOr  Operator = "OR"
+	OpNot Operator = "NOT"
+)
+
+// keyOperators lists the operators that appear as condition key suffixes.
+// Longer suffixes come first so "IS NOT NULL" is not mistaken for "IS NULL".
+var keyOperators = []Operator{
+	OpIsNotNull, OpStartsWith, OpEndsWith, OpIsNull, OpBetween, OpNotIn, OpExists, OpRegex,
+	OpILike, OpLike, OpIn, OpNotEqual, OpGreaterOrEqual, OpLessOrEqual, OpGreaterThan, OpLessThan,
+}
+
+// RegexPattern is the value of an OpRegex condition. Options are MongoDB regular expression
+// options and may combine "i", "m", "s" and "x".
+type RegexPattern struct {
+	Pattern string
+	Options string
+}
+
+// Condition is a single field comparison of an identifier, or a group of the conditions of
+// other identifiers when Operator is OpAnd, OpOr or OpNot
+type Condition struct {
+	Field    string
+	Operator Operator
+	Value    interface{}
+	// Operands holds one list of conditions per grouped identifier, each ANDed internally
+	Operands [][]Condition
+}
+
+// IsGroup reports whether the condition combines other identifiers instead of comparing a field
+func (c Condition) IsGroup() bool {
+	return c.Operator == OpAnd || c.Operator == OpOr || c.Operator == OpNot
+}
+
+// ParseKey splits a condition map key such as "age >" or "status IN" into its field and
+// operator. Keys without an operator suffix compare for equality.
+func ParseKey(key string) (string, Operator) {
+	for _, op := range keyOperators {
+		if field, ok := strings.CutSuffix(key, " "+string(op)); ok {
+			return field, op
+		}
+	}
+	return key, OpEqual
+}
+
+// Key returns the condition map key for field and op, the inverse of ParseKey
+func Key(field string, op Operator) string {
+	if op == OpEqual {
+		return field
+	}
+	return field + " " + string(op)
+}
+
+// Conditions returns the conditions of any types.Identifier, sorted by key.
+// A UnifiedIdentifier yields the conditions of whichever backend identifier it wraps, or its own
+// when it is backend-neutral, followed by its Or, And and Not groups; other implementations are
+// read through ToMap. A nil filter has no conditions and matches everything. Conditions that
+// cannot be turned into a query are reported as ErrInvalidFilter.
+func Conditions(filter types.Identifier) ([]Condition, error) {
+	if isNil(filter) {
+		return nil, nil
+	}
+
+	var conditionMap map[string]interface{}
+	var groups []group
+	if unified, ok := filter.(*UnifiedIdentifier); ok {
+		switch {
+		case unified.mongoID != nil:
+			conditionMap = unified.conditionMap(unified.mongoID.ToMap())
+		case unified.postgresID != nil:
+			conditionMap = unified.conditionMap(unified.postgresID.ToMap())
+		default:
+			conditionMap = unified.extra
+		}
+		groups = unified.groups
+	} else {
+		conditionMap = filter.ToMap()
+		if len(conditionMap) == 0 && len(filter.ToBSON()) > 0 {
+			return nil, fmt.Errorf("%w: %T exposes no conditions through ToMap", repoErrors.ErrInvalidFilter, filter)
+		}
+	}
+
+	keys := sortedKeys(conditionMap)
+	conditions := make([]Condition, 0, len(keys))
+	for _, key := range keys {
+		field, op := ParseKey(key)
+		condition := Condition{Field: strings.TrimSpace(field), Operator: op, Value: conditionMap[key]}
+		if err := condition.validate(); err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	for _, g := range groups {
+		condition, err := g.condition()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+	}
+
+	return conditions, nil
+}
+
+// condition resolves the grouped identifiers into a group condition
+func (g group) condition() (Condition, error) {
+	if len(g.operands) == 0 {
+		return Condition{}, fmt.Errorf("%w: %s group without operands", repoErrors.ErrInvalidFilter, g.operator)
+	}
+
+	condition := Condition{Operator: g.operator, Operands: make([][]Condition, 0, len(g.operands))}
+	for _, operand := range g.operands {
+		conditions, err := Conditions(operand)
+		if err != nil {
+			return Condition{}, err
+		}
+		if len(conditions) == 0 {
+			return Condition{}, fmt.Errorf("%w: %s group with an empty operand", repoErrors.ErrInvalidFilter, g.operator)
+		}
+		condition.Operands = append(condition.Operands, conditions)
+	}
+
+	return condition, nil
+}
+
+// validate checks that the condition names a field and carries a value its operator can use
+func (c Condition) validate() error {
+	if c.Field == "" {
+		return fmt.Errorf("%w: condition without a field", repoErrors.ErrInvalidFilter)
+	}
+
+	switch c.Operator {
+	case OpIn, OpNotIn:
+		if !isList(c.Value) {
+			return fmt.Errorf("%w: %s on field %q needs a list, got %T", repoErrors.ErrInvalidFilter, c.Operator, c.Field, c.Value)
+		}
+	case OpBetween:
+		if bounds, ok := c.Value.([]interface{}); !ok || len(bounds) != 2 {
+			return fmt.Errorf("%w: BETWEEN on field %q needs two bounds", repoErrors.ErrInvalidFilter, c.Field)
+		}
+	case OpLike, OpILike, OpStartsWith, OpEndsWith:
+		if _, ok := c.Value.(string); !ok {
+			return fmt.Errorf("%w: %s on field %q needs a string pattern, got %T", repoErrors.ErrInvalidFilter, c.Operator, c.Field, c.Value)
+		}
+	case OpExists:
+		if _, ok := c.Value.(bool); !ok {
+			return fmt.Errorf("%w: EXISTS on field %q needs a bool, got %T", repoErrors.ErrInvalidFilter, c.Field, c.Value)
+		}
+	case OpRegex:
+		regex, ok := c.Value.(RegexPattern)
+		if !ok {
+			return fmt.Errorf("%w: REGEX on field %q needs a RegexPattern, got %T", repoErrors.ErrInvalidFilter, c.Field, c.Value)
+		}
+		if strings.Trim(regex.Options, "imsx") != "" {
+			return fmt.Errorf("%w: REGEX on field %q has unsupported options %q", repoErrors.ErrInvalidFilter, c.Field, regex.Options)
+		}
+	}
+
+	return nil
+}
+
+// sortedKeys returns the keys of m in ascending order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isNil reports whether v is nil or a nil pointer behind an interface
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Ptr && rv.IsNil()
+}
+
+// isList reports whether v is a slice or array
+func isList(v interface{}) bool {
+	kind := reflect.ValueOf(v).Kind()
+	return kind == reflect.Slice || kind == reflect.Array
+}
@@ -0,0 +1,238 @@
+package identifier_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+)
+
+// mapIdentifier is a types.Identifier that is not a UnifiedIdentifier
+type mapIdentifier map[string]interface{}
+
+func (m mapIdentifier) ToBSON() map[string]interface{} { return m }
+func (m mapIdentifier) Has(field string) bool          { _, ok := m[field]; return ok }
+func (m mapIdentifier) ToMap() map[string]interface{}  { return m }
+func (m mapIdentifier) Equal(field string, value interface{}) types.Identifier {
+	m[field] = value
+	return m
+}
+func (m mapIdentifier) GreaterThan(field string, value interface{}) types.Identifier {
+	m[field+" >"] = value
+	return m
+}
+func (m mapIdentifier) LessThan(field string, value interface{}) types.Identifier {
+	m[field+" <"] = value
+	return m
+}
+func (m mapIdentifier) Between(field string, min, max interface{}) types.Identifier {
+	m[field+" BETWEEN"] = []interface{}{min, max}
+	return m
+}
+func (m mapIdentifier) Like(field string, pattern string) types.Identifier {
+	m[field+" LIKE"] = pattern
+	return m
+}
+func (m mapIdentifier) In(field string, values []interface{}) types.Identifier {
+	m[field+" IN"] = values
+	return m
+}
+func (m mapIdentifier) NotEqual(field string, value interface{}) types.Identifier {
+	m[field+" !="] = value
+	return m
+}
+func (m mapIdentifier) GreaterOrEqual(field string, value interface{}) types.Identifier {
+	m[field+" >="] = value
+	return m
+}
+func (m mapIdentifier) LessOrEqual(field string, value interface{}) types.Identifier {
+	m[field+" <="] = value
+	return m
+}
+func (m mapIdentifier) NotIn(field string, values []interface{}) types.Identifier {
+	m[field+" NOT IN"] = values
+	return m
+}
+func (m mapIdentifier) IsNull(field string) types.Identifier {
+	m[field+" IS NULL"] = true
+	return m
+}
+func (m mapIdentifier) IsNotNull(field string) types.Identifier {
+	m[field+" IS NOT NULL"] = true
+	return m
+}
+func (m mapIdentifier) Exists(field string, exists bool) types.Identifier {
+	m[field+" EXISTS"] = exists
+	return m
+}
+func (m mapIdentifier) StartsWith(field string, prefix string) types.Identifier {
+	m[field+" STARTS WITH"] = prefix
+	return m
+}
+func (m mapIdentifier) EndsWith(field string, suffix string) types.Identifier {
+	m[field+" ENDS WITH"] = suffix
+	return m
+}
+func (m mapIdentifier) ILike(field string, pattern string) types.Identifier {
+	m[field+" ILIKE"] = pattern
+	return m
+}
+func (m mapIdentifier) Regex(field string, pattern string, options string) types.Identifier {
+	m[field+" REGEX"] = identifier.RegexPattern{Pattern: pattern, Options: options}
+	return m
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		key      string
+		field    string
+		operator identifier.Operator
+	}{
+		{"name", "name", identifier.OpEqual},
+		{"age >", "age", identifier.OpGreaterThan},
+		{"age <", "age", identifier.OpLessThan},
+		{"status IN", "status", identifier.OpIn},
+		{"email LIKE", "email", identifier.OpLike},
+		{"score BETWEEN", "score", identifier.OpBetween},
+		{"deletedAt IS NULL", "deletedAt", identifier.OpIsNull},
+		{"deletedAt IS NOT NULL", "deletedAt", identifier.OpIsNotNull},
+		{"status !=", "status", identifier.OpNotEqual},
+		{"age >=", "age", identifier.OpGreaterOrEqual},
+		{"age <=", "age", identifier.OpLessOrEqual},
+		{"status NOT IN", "status", identifier.OpNotIn},
+		{"email EXISTS", "email", identifier.OpExists},
+		{"name STARTS WITH", "name", identifier.OpStartsWith},
+		{"name ENDS WITH", "name", identifier.OpEndsWith},
+		{"name ILIKE", "name", identifier.OpILike},
+		{"name REGEX", "name", identifier.OpRegex},
+	}
+
+	for _, tt := range tests {
+		field, op := identifier.ParseKey(tt.key)
+		if field != tt.field || op != tt.operator {
+			t.Errorf("ParseKey(%q) = %q, %q, want %q, %q", tt.key, field, op, tt.field, tt.operator)
+		}
+	}
+}
+
+func TestConditions_Nil(t *testing.T) {
+	var typedNil *identifier.UnifiedIdentifier
+
+	for _, filter := range []types.Identifier{nil, typedNil} {
+		conditions, err := identifier.Conditions(filter)
+		if err != nil {
+			t.Fatalf("Expected nil filter to match all, got %v", err)
+		}
+		if len(conditions) != 0 {
+			t.Errorf("Expected no conditions, got %v", conditions)
+		}
+	}
+}
+
+func TestConditions_UnifiedIdentifier(t *testing.T) {
+	filter := identifier.NewMongoIdentifier().Equal("name", "test").GreaterThan("age", 18)
+
+	conditions, err := identifier.Conditions(filter)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []identifier.Condition{
+		{Field: "age", Operator: identifier.OpGreaterThan, Value: 18},
+		{Field: "name", Operator: identifier.OpEqual, Value: "test"},
+	}
+	if !reflect.DeepEqual(conditions, expected) {
+		t.Errorf("Expected %v, got %v", expected, conditions)
+	}
+}
+
+func TestConditions_ForeignIdentifier(t *testing.T) {
+	filter := mapIdentifier{}.In("status", []interface{}{"active"}).Between("score", 1, 10)
+
+	conditions, err := identifier.Conditions(filter)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []identifier.Condition{
+		{Field: "score", Operator: identifier.OpBetween, Value: []interface{}{1, 10}},
+		{Field: "status", Operator: identifier.OpIn, Value: []interface{}{"active"}},
+	}
+	if !reflect.DeepEqual(conditions, expected) {
+		t.Errorf("Expected %v, got %v", expected, conditions)
+	}
+}
+
+func TestConditions_Groups(t *testing.T) {
+	filter := identifier.NewPostgresIdentifier()
+	filter.Equal("tenant", "acme")
+	filter.Or(
+		identifier.NewPostgresIdentifier().Equal("status", "active"),
+		identifier.NewPostgresIdentifier().Equal("role", "admin").GreaterThan("age", 18),
+	)
+	filter.Not(identifier.NewPostgresIdentifier().Equal("deleted", true))
+
+	conditions, err := identifier.Conditions(filter)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []identifier.Condition{
+		{Field: "tenant", Operator: identifier.OpEqual, Value: "acme"},
+		{Operator: identifier.OpOr, Operands: [][]identifier.Condition{
+			{{Field: "status", Operator: identifier.OpEqual, Value: "active"}},
+			{
+				{Field: "age", Operator: identifier.OpGreaterThan, Value: 18},
+				{Field: "role", Operator: identifier.OpEqual, Value: "admin"},
+			},
+		}},
+		{Operator: identifier.OpNot, Operands: [][]identifier.Condition{
+			{{Field: "deleted", Operator: identifier.OpEqual, Value: true}},
+		}},
+	}
+	if !reflect.DeepEqual(conditions, expected) {
+		t.Errorf("Expected %v, got %v", expected, conditions)
+	}
+}
+
+func TestConditions_InvalidGroup(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter types.Identifier
+	}{
+		{"OR without operands", identifier.NewMongoIdentifier().Or()},
+		{"empty operand", identifier.NewMongoIdentifier().And(identifier.NewMongoIdentifier())},
+		{"invalid operand", identifier.NewMongoIdentifier().Not(mapIdentifier{"status IN": "active"})},
+	}
+
+	for _, tt := range tests {
+		if _, err := identifier.Conditions(tt.filter); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+			t.Errorf("%s: expected ErrInvalidFilter, got %v", tt.name, err)
+		}
+	}
+}
+
+func TestConditions_InvalidFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter types.Identifier
+	}{
+		{"missing field", mapIdentifier{" >": 1}},
+		{"IN without list", mapIdentifier{"status IN": "active"}},
+		{"BETWEEN with one bound", mapIdentifier{"score BETWEEN": []interface{}{1}}},
+		{"LIKE without string", mapIdentifier{"name LIKE": 42}},
+		{"NOT IN without list", mapIdentifier{"status NOT IN": "active"}},
+		{"EXISTS without bool", mapIdentifier{"email EXISTS": "yes"}},
+		{"REGEX without pattern", mapIdentifier{"name REGEX": "^a"}},
+		{"REGEX with unknown option", mapIdentifier{}.Regex("name", "^a", "g")},
+	}
+
+	for _, tt := range tests {
+		if _, err := identifier.Conditions(tt.filter); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+			t.Errorf("%s: expected ErrInvalidFilter, got %v", tt.name, err)
+		}
+	}
+}
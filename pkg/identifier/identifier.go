@@ -1,15 +1,51 @@
 package identifier
 
 import (
+	"strings"
+
 	"github.com/arash-mosavi/go-base-repository/pkg/types"
 	mongoIdentifier "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
 	postgresIdentifier "github.com/arash-mosavi/postgrs-unit-of-work-system/pkg/identifier"
+	"go.mongodb.org/mongo-driver/bson"
 )
 
-// UnifiedIdentifier provides a unified interface for both MongoDB and PostgreSQL identifiers
+// UnifiedIdentifier provides a unified interface for both MongoDB and PostgreSQL identifiers.
+// It either wraps a backend identifier or, when created with New, wraps none and records its
+// conditions itself.
 type UnifiedIdentifier struct {
 	mongoID    mongoIdentifier.IIdentifier
 	postgresID postgresIdentifier.IIdentifier
+	// extra holds the conditions the wrapped backend identifier cannot express, or every
+	// condition of a backend-neutral identifier, keyed like the backend identifiers
+	extra  map[string]interface{}
+	groups []group
+	// all marks an identifier created by All
+	all bool
+}
+
+// group combines the conditions of other identifiers with a boolean operator
+type group struct {
+	operator Operator
+	operands []types.Identifier
+}
+
+// New creates a backend-neutral identifier. Its conditions are rendered as BSON or SQL only
+// when it is handed to a repository, so the same identifier works against either store.
+func New() *UnifiedIdentifier {
+	return &UnifiedIdentifier{}
+}
+
+// All creates a backend-neutral identifier that explicitly matches every entity. Writes that
+// change every matching entity at once reject a filter without conditions unless it is All, so
+// a missing filter cannot rewrite or empty a whole table or collection by accident.
+func All() *UnifiedIdentifier {
+	return &UnifiedIdentifier{all: true}
+}
+
+// MatchesAll reports whether filter was created by All
+func MatchesAll(filter types.Identifier) bool {
+	unified, ok := filter.(*UnifiedIdentifier)
+	return ok && unified != nil && unified.all
 }
 
 // NewMongoIdentifier creates a new MongoDB identifier
@@ -28,6 +64,9 @@ func NewPostgresIdentifier() *UnifiedIdentifier {
 
 // Equal adds an equality condition
 func (u *UnifiedIdentifier) Equal(field string, value interface{}) types.Identifier {
+	if u.isNeutral() {
+		return u.add(field, OpEqual, value)
+	}
 	if u.mongoID != nil {
 		u.mongoID = u.mongoID.Equal(field, value)
 	}
@@ -39,6 +78,9 @@ func (u *UnifiedIdentifier) Equal(field string, value interface{}) types.Identif
 
 // GreaterThan adds a greater than condition
 func (u *UnifiedIdentifier) GreaterThan(field string, value interface{}) types.Identifier {
+	if u.isNeutral() {
+		return u.add(field, OpGreaterThan, value)
+	}
 	if u.mongoID != nil {
 		u.mongoID = u.mongoID.GreaterThan(field, value)
 	}
@@ -50,6 +92,9 @@ func (u *UnifiedIdentifier) GreaterThan(field string, value interface{}) types.I
 
 // LessThan adds a less than condition
 func (u *UnifiedIdentifier) LessThan(field string, value interface{}) types.Identifier {
+	if u.isNeutral() {
+		return u.add(field, OpLessThan, value)
+	}
 	if u.mongoID != nil {
 		u.mongoID = u.mongoID.LessThan(field, value)
 	}
@@ -61,6 +106,9 @@ func (u *UnifiedIdentifier) LessThan(field string, value interface{}) types.Iden
 
 // Between adds a between condition
 func (u *UnifiedIdentifier) Between(field string, min, max interface{}) types.Identifier {
+	if u.isNeutral() {
+		return u.add(field, OpBetween, []interface{}{min, max})
+	}
 	if u.mongoID != nil {
 		u.mongoID = u.mongoID.Between(field, min, max)
 	}
@@ -72,6 +120,9 @@ func (u *UnifiedIdentifier) Between(field string, min, max interface{}) types.Id
 
 // Like adds a like condition
 func (u *UnifiedIdentifier) Like(field string, pattern string) types.Identifier {
+	if u.isNeutral() {
+		return u.add(field, OpLike, pattern)
+	}
 	if u.mongoID != nil {
 		u.mongoID = u.mongoID.Like(field, pattern)
 	}
@@ -83,6 +134,9 @@ func (u *UnifiedIdentifier) Like(field string, pattern string) types.Identifier
 
 // In adds an in condition
 func (u *UnifiedIdentifier) In(field string, values []interface{}) types.Identifier {
+	if u.isNeutral() {
+		return u.add(field, OpIn, values)
+	}
 	if u.mongoID != nil {
 		u.mongoID = u.mongoID.In(field, values)
 	}
@@ -92,31 +146,202 @@ func (u *UnifiedIdentifier) In(field string, values []interface{}) types.Identif
 	return u
 }
 
+// NotEqual adds a not equal condition
+func (u *UnifiedIdentifier) NotEqual(field string, value interface{}) types.Identifier {
+	return u.add(field, OpNotEqual, value)
+}
+
+// GreaterOrEqual adds a greater than or equal condition
+func (u *UnifiedIdentifier) GreaterOrEqual(field string, value interface{}) types.Identifier {
+	return u.add(field, OpGreaterOrEqual, value)
+}
+
+// LessOrEqual adds a less than or equal condition
+func (u *UnifiedIdentifier) LessOrEqual(field string, value interface{}) types.Identifier {
+	return u.add(field, OpLessOrEqual, value)
+}
+
+// NotIn adds a not in condition
+func (u *UnifiedIdentifier) NotIn(field string, values []interface{}) types.Identifier {
+	return u.add(field, OpNotIn, values)
+}
+
+// IsNull adds a condition matching a missing or null field
+func (u *UnifiedIdentifier) IsNull(field string) types.Identifier {
+	return u.add(field, OpIsNull, true)
+}
+
+// IsNotNull adds a condition matching a present, non-null field
+func (u *UnifiedIdentifier) IsNotNull(field string) types.Identifier {
+	return u.add(field, OpIsNotNull, true)
+}
+
+// Exists adds a condition on whether the field is present
+func (u *UnifiedIdentifier) Exists(field string, exists bool) types.Identifier {
+	return u.add(field, OpExists, exists)
+}
+
+// StartsWith adds a condition matching values beginning with prefix, taken literally
+func (u *UnifiedIdentifier) StartsWith(field string, prefix string) types.Identifier {
+	return u.add(field, OpStartsWith, prefix)
+}
+
+// EndsWith adds a condition matching values ending with suffix, taken literally
+func (u *UnifiedIdentifier) EndsWith(field string, suffix string) types.Identifier {
+	return u.add(field, OpEndsWith, suffix)
+}
+
+// ILike adds a case-insensitive like condition
+func (u *UnifiedIdentifier) ILike(field string, pattern string) types.Identifier {
+	return u.add(field, OpILike, pattern)
+}
+
+// Regex adds a regular expression condition; options may combine "i", "m", "s" and "x"
+func (u *UnifiedIdentifier) Regex(field string, pattern string, options string) types.Identifier {
+	return u.add(field, OpRegex, RegexPattern{Pattern: pattern, Options: options})
+}
+
+// add records a condition the wrapped backend identifier cannot express, or any condition
+// of a backend-neutral identifier
+func (u *UnifiedIdentifier) add(field string, op Operator, value interface{}) types.Identifier {
+	if u.extra == nil {
+		u.extra = make(map[string]interface{})
+	}
+	u.extra[Key(field, op)] = value
+	return u
+}
+
+// isNeutral reports whether the identifier wraps no backend identifier
+func (u *UnifiedIdentifier) isNeutral() bool {
+	return u.mongoID == nil && u.postgresID == nil
+}
+
+// Or adds a group matching when any of the identifiers matches
+func (u *UnifiedIdentifier) Or(identifiers ...types.Identifier) types.Identifier {
+	u.groups = append(u.groups, group{operator: OpOr, operands: identifiers})
+	return u
+}
+
+// And adds a group matching when all of the identifiers match
+func (u *UnifiedIdentifier) And(identifiers ...types.Identifier) types.Identifier {
+	u.groups = append(u.groups, group{operator: OpAnd, operands: identifiers})
+	return u
+}
+
+// Not adds a group matching when the identifier does not match
+func (u *UnifiedIdentifier) Not(identifier types.Identifier) types.Identifier {
+	u.groups = append(u.groups, group{operator: OpNot, operands: []types.Identifier{identifier}})
+	return u
+}
+
 // ToBSON returns the MongoDB BSON representation
 func (u *UnifiedIdentifier) ToBSON() map[string]interface{} {
-	if u.mongoID != nil {
-		return u.mongoID.ToBSON()
+	var filter map[string]interface{}
+	switch {
+	case u.mongoID != nil:
+		filter = u.mongoID.ToBSON()
+	case u.postgresID != nil:
+		return make(map[string]interface{})
+	default:
+		filter = make(map[string]interface{})
+	}
+	if len(u.extra) == 0 && len(u.groups) == 0 {
+		return filter
+	}
+
+	extended := make(map[string]interface{}, len(filter)+len(u.extra)+1)
+	for key, value := range filter {
+		extended[key] = value
+	}
+	for _, key := range sortedKeys(u.extra) {
+		field, op := ParseKey(key)
+		MergeCondition(bson.M(extended), field, Condition{Field: field, Operator: op, Value: u.extra[key]}.BSON())
 	}
-	return make(map[string]interface{})
+	if len(u.groups) == 0 {
+		return extended
+	}
+
+	// groups are ANDed with the field conditions and each other
+	clauses := make([]interface{}, 0, len(u.groups))
+	for _, g := range u.groups {
+		operands := make([]interface{}, 0, len(g.operands))
+		for _, operand := range g.operands {
+			if !isNil(operand) {
+				operands = append(operands, operand.ToBSON())
+			}
+		}
+		clauses = append(clauses, map[string]interface{}{groupOperators[g.operator]: operands})
+	}
+
+	if len(clauses) == 1 {
+		for key, value := range clauses[0].(map[string]interface{}) {
+			extended[key] = value
+		}
+	} else {
+		extended["$and"] = clauses
+	}
+
+	return extended
 }
 
-// ToMap returns the PostgreSQL map representation
+// ToMap returns the PostgreSQL map representation. Or, And and Not groups cannot be expressed
+// as map keys and are only rendered by the repositories.
 func (u *UnifiedIdentifier) ToMap() map[string]interface{} {
-	if u.postgresID != nil {
-		return u.postgresID.ToMap()
+	switch {
+	case u.postgresID != nil:
+		return u.conditionMap(u.postgresID.ToMap())
+	case u.mongoID != nil:
+		return make(map[string]interface{})
+	default:
+		return u.conditionMap(make(map[string]interface{}))
 	}
-	return make(map[string]interface{})
 }
 
-// Has checks if a field exists in the identifier
+// conditionMap returns base extended by the conditions of extra
+func (u *UnifiedIdentifier) conditionMap(base map[string]interface{}) map[string]interface{} {
+	if len(u.extra) == 0 {
+		return base
+	}
+
+	merged := make(map[string]interface{}, len(base)+len(u.extra))
+	for key, value := range base {
+		merged[key] = value
+	}
+	for key, value := range u.extra {
+		merged[key] = value
+	}
+	return merged
+}
+
+// Has reports whether a condition compares field, whatever its operator, including the
+// conditions inside Or, And and Not groups
 func (u *UnifiedIdentifier) Has(field string) bool {
+	if hasField(u.extra, field) {
+		return true
+	}
+	for _, g := range u.groups {
+		for _, operand := range g.operands {
+			if !isNil(operand) && operand.Has(field) {
+				return true
+			}
+		}
+	}
 	if u.mongoID != nil {
 		return u.mongoID.Has(field)
 	}
 	if u.postgresID != nil {
 		// Note: PostgreSQL identifier doesn't have Has method, so we check the map
-		_, exists := u.postgresID.ToMap()[field]
-		return exists
+		return hasField(u.postgresID.ToMap(), field)
+	}
+	return false
+}
+
+// hasField reports whether a key of the condition map conditions compares field
+func hasField(conditions map[string]interface{}, field string) bool {
+	for key := range conditions {
+		if name, _ := ParseKey(key); strings.TrimSpace(name) == field {
+			return true
+		}
 	}
 	return false
 }
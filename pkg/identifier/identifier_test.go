@@ -1,10 +1,15 @@
 package identifier_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
 	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func TestUnifiedIdentifier_MongoDB(t *testing.T) {
@@ -129,3 +134,303 @@ func TestUnifiedIdentifier_TypeSafety(t *testing.T) {
 		t.Error("Method chaining should work for both identifiers")
 	}
 }
+
+func TestUnifiedIdentifier_Groups(t *testing.T) {
+	filter := identifier.NewMongoIdentifier()
+	filter.Equal("tenant", "acme")
+	filter.Or(
+		identifier.NewMongoIdentifier().Equal("status", "active"),
+		identifier.NewMongoIdentifier().Equal("role", "admin"),
+	)
+
+	expected := map[string]interface{}{
+		"tenant": "acme",
+		"$or": []interface{}{
+			map[string]interface{}{"status": "active"},
+			map[string]interface{}{"role": "admin"},
+		},
+	}
+	if got := filter.ToBSON(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	filter.Not(identifier.NewMongoIdentifier().Equal("deleted", true))
+
+	expected = map[string]interface{}{
+		"tenant": "acme",
+		"$and": []interface{}{
+			map[string]interface{}{"$or": []interface{}{
+				map[string]interface{}{"status": "active"},
+				map[string]interface{}{"role": "admin"},
+			}},
+			map[string]interface{}{"$nor": []interface{}{
+				map[string]interface{}{"deleted": true},
+			}},
+		},
+	}
+	if got := filter.ToBSON(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+// operatorCases builds one condition with each operator beyond the original builder methods
+var operatorCases = []struct {
+	name  string
+	build func(types.Identifier) types.Identifier
+	bson  map[string]interface{}
+	sql   string
+	vars  []interface{}
+}{
+	{
+		name:  "NotEqual",
+		build: func(id types.Identifier) types.Identifier { return id.NotEqual("status", "banned") },
+		bson:  map[string]interface{}{"status": bson.M{"$ne": "banned"}},
+		sql:   `"status" <> $1`,
+		vars:  []interface{}{"banned"},
+	},
+	{
+		name:  "GreaterOrEqual",
+		build: func(id types.Identifier) types.Identifier { return id.GreaterOrEqual("age", 18) },
+		bson:  map[string]interface{}{"age": bson.M{"$gte": 18}},
+		sql:   `"age" >= $1`,
+		vars:  []interface{}{18},
+	},
+	{
+		name:  "LessOrEqual",
+		build: func(id types.Identifier) types.Identifier { return id.LessOrEqual("age", 65) },
+		bson:  map[string]interface{}{"age": bson.M{"$lte": 65}},
+		sql:   `"age" <= $1`,
+		vars:  []interface{}{65},
+	},
+	{
+		name: "NotIn",
+		build: func(id types.Identifier) types.Identifier {
+			return id.NotIn("status", []interface{}{"banned", "closed"})
+		},
+		bson: map[string]interface{}{"status": bson.M{"$nin": []interface{}{"banned", "closed"}}},
+		sql:  `"status" NOT IN ($1,$2)`,
+		vars: []interface{}{"banned", "closed"},
+	},
+	{
+		name:  "IsNull",
+		build: func(id types.Identifier) types.Identifier { return id.IsNull("deletedAt") },
+		bson:  map[string]interface{}{"deletedAt": nil},
+		sql:   `"deletedAt" IS NULL`,
+	},
+	{
+		name:  "IsNotNull",
+		build: func(id types.Identifier) types.Identifier { return id.IsNotNull("deletedAt") },
+		bson:  map[string]interface{}{"deletedAt": bson.M{"$ne": nil}},
+		sql:   `"deletedAt" IS NOT NULL`,
+	},
+	{
+		name:  "Exists",
+		build: func(id types.Identifier) types.Identifier { return id.Exists("email", false) },
+		bson:  map[string]interface{}{"email": bson.M{"$exists": false}},
+		sql:   `"email" IS NULL`,
+	},
+	{
+		name:  "StartsWith",
+		build: func(id types.Identifier) types.Identifier { return id.StartsWith("code", "50%_a.") },
+		bson:  map[string]interface{}{"code": bson.M{"$regex": `^50%_a\.`}},
+		sql:   `"code" LIKE $1`,
+		vars:  []interface{}{`50\%\_a.%`},
+	},
+	{
+		name:  "EndsWith",
+		build: func(id types.Identifier) types.Identifier { return id.EndsWith("email", "@example.com") },
+		bson:  map[string]interface{}{"email": bson.M{"$regex": `@example\.com$`}},
+		sql:   `"email" LIKE $1`,
+		vars:  []interface{}{"%@example.com"},
+	},
+	{
+		name:  "ILike",
+		build: func(id types.Identifier) types.Identifier { return id.ILike("name", "jo_n%") },
+		bson:  map[string]interface{}{"name": bson.M{"$regex": "^jo.n.*$", "$options": "i"}},
+		sql:   `"name" ILIKE $1`,
+		vars:  []interface{}{"jo_n%"},
+	},
+	{
+		name:  "Regex",
+		build: func(id types.Identifier) types.Identifier { return id.Regex("name", "^jo", "i") },
+		bson:  map[string]interface{}{"name": bson.M{"$regex": "^jo", "$options": "i"}},
+		sql:   `"name" ~* $1`,
+		vars:  []interface{}{"(?p)^jo"},
+	},
+	{
+		name:  "Regex multiline",
+		build: func(id types.Identifier) types.Identifier { return id.Regex("bio", "^a.b$", "ms") },
+		bson:  map[string]interface{}{"bio": bson.M{"$regex": "^a.b$", "$options": "ms"}},
+		sql:   `"bio" ~ $1`,
+		vars:  []interface{}{"(?w)^a.b$"},
+	},
+}
+
+func TestUnifiedIdentifier_OperatorBSON(t *testing.T) {
+	for _, tt := range operatorCases {
+		for _, id := range []*identifier.UnifiedIdentifier{identifier.NewMongoIdentifier(), identifier.New()} {
+			got := tt.build(id).ToBSON()
+			if !reflect.DeepEqual(got, tt.bson) {
+				t.Errorf("%s: expected %v, got %v", tt.name, tt.bson, got)
+			}
+		}
+	}
+}
+
+func TestUnifiedIdentifier_OperatorSQL(t *testing.T) {
+	for _, tt := range operatorCases {
+		for _, id := range []*identifier.UnifiedIdentifier{identifier.NewPostgresIdentifier(), identifier.New()} {
+			sql, vars := renderSQL(t, tt.build(id))
+			if sql != tt.sql {
+				t.Errorf("%s: expected SQL %s, got %s", tt.name, tt.sql, sql)
+			}
+			if len(vars) != 0 || len(tt.vars) != 0 {
+				if !reflect.DeepEqual(vars, tt.vars) {
+					t.Errorf("%s: expected vars %v, got %v", tt.name, tt.vars, vars)
+				}
+			}
+		}
+	}
+}
+
+func TestAll(t *testing.T) {
+	conditions, err := identifier.Conditions(identifier.All())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(conditions) != 0 {
+		t.Errorf("Expected no conditions, got %v", conditions)
+	}
+
+	if !identifier.MatchesAll(identifier.All()) {
+		t.Error("Expected All to match all")
+	}
+	for _, filter := range []types.Identifier{nil, identifier.New(), (*identifier.UnifiedIdentifier)(nil)} {
+		if identifier.MatchesAll(filter) {
+			t.Errorf("Expected %#v not to match all", filter)
+		}
+	}
+}
+
+func TestNew_LikePattern(t *testing.T) {
+	filter := identifier.New().Like("code", `50\%_a.(b)%`)
+
+	expectedBSON := map[string]interface{}{"code": bson.M{"$regex": `^50%.a\.\(b\).*$`}}
+	if got := filter.ToBSON(); !reflect.DeepEqual(got, expectedBSON) {
+		t.Errorf("Expected %v, got %v", expectedBSON, got)
+	}
+
+	sql, vars := renderSQL(t, filter)
+	if sql != `"code" LIKE $1` {
+		t.Errorf("Expected SQL %s, got %s", `"code" LIKE $1`, sql)
+	}
+	if !reflect.DeepEqual(vars, []interface{}{`50\%_a.(b)%`}) {
+		t.Errorf("Expected the pattern as is, got %v", vars)
+	}
+}
+
+func TestNewMongoIdentifier_LikeRegex(t *testing.T) {
+	filter := identifier.NewMongoIdentifier().Like("email", "%@example.com")
+
+	expected := map[string]interface{}{"email": bson.M{"$regex": "%@example.com", "$options": "i"}}
+	if got := filter.ToBSON(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected the legacy regex %v, got %v", expected, got)
+	}
+}
+
+func TestNew_BackendNeutral(t *testing.T) {
+	filter := identifier.New()
+	filter.Equal("name", "John").
+		GreaterThan("age", 18).
+		Between("score", 50, 100).
+		In("status", []interface{}{"active", "pending"})
+	filter.Or(
+		identifier.New().Equal("role", "admin"),
+		identifier.New().Like("email", "%@example.com"),
+	)
+
+	expectedBSON := map[string]interface{}{
+		"name":   "John",
+		"age":    bson.M{"$gt": 18},
+		"score":  bson.M{"$gte": 50, "$lte": 100},
+		"status": bson.M{"$in": []interface{}{"active", "pending"}},
+		"$or": []interface{}{
+			map[string]interface{}{"role": "admin"},
+			map[string]interface{}{"email": bson.M{"$regex": `^.*@example\.com$`}},
+		},
+	}
+	if got := filter.ToBSON(); !reflect.DeepEqual(got, expectedBSON) {
+		t.Errorf("Expected %v, got %v", expectedBSON, got)
+	}
+
+	sql, vars := renderSQL(t, filter)
+	expectedSQL := `"age" > $1 AND "name" = $2 AND ("score" BETWEEN $3 AND $4) AND "status" IN ($5,$6) AND ("role" = $7 OR "email" LIKE $8)`
+	if sql != expectedSQL {
+		t.Errorf("Expected SQL %s, got %s", expectedSQL, sql)
+	}
+	expectedVars := []interface{}{18, "John", 50, 100, "active", "pending", "admin", "%@example.com"}
+	if !reflect.DeepEqual(vars, expectedVars) {
+		t.Errorf("Expected vars %v, got %v", expectedVars, vars)
+	}
+
+	if !filter.Has("name") {
+		t.Error("Should have the 'name' field")
+	}
+	if filter.GetMongoIdentifier() != nil || filter.GetPostgresIdentifier() != nil {
+		t.Error("Backend-neutral identifier should not wrap a backend identifier")
+	}
+}
+
+func TestNew_Has(t *testing.T) {
+	filter := identifier.New()
+	filter.GreaterThan("age", 1).IsNull("deletedAt")
+	filter.Or(
+		identifier.New().Equal("role", "admin"),
+		identifier.New().Not(identifier.New().Like("email", "%@example.com")),
+	)
+
+	for _, field := range []string{"age", "deletedAt", "role", "email"} {
+		if !filter.Has(field) {
+			t.Errorf("Expected a condition on %s", field)
+		}
+	}
+	for _, field := range []string{"name", "ag", "IS NULL"} {
+		if filter.Has(field) {
+			t.Errorf("Expected no condition on %s", field)
+		}
+	}
+}
+
+func TestUnifiedIdentifier_MergedBounds(t *testing.T) {
+	filter := identifier.NewMongoIdentifier()
+	filter.GreaterOrEqual("age", 18).LessOrEqual("age", 65)
+
+	expected := map[string]interface{}{"age": bson.M{"$gte": 18, "$lte": 65}}
+	if got := filter.ToBSON(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	sql, _ := renderSQL(t, identifier.NewPostgresIdentifier().GreaterOrEqual("age", 18).LessOrEqual("age", 65))
+	if expected := `"age" <= $1 AND "age" >= $2`; sql != expected {
+		t.Errorf("Expected SQL %s, got %s", expected, sql)
+	}
+}
+
+// renderSQL renders the conditions of filter as a PostgreSQL WHERE clause body
+func renderSQL(t *testing.T, filter types.Identifier) (string, []interface{}) {
+	t.Helper()
+
+	conditions, err := identifier.Conditions(filter)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("Failed to open dialector: %v", err)
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	clause.Where{Exprs: identifier.Expressions(conditions)}.Build(stmt)
+	return stmt.SQL.String(), stmt.Vars
+}
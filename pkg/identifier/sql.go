@@ -0,0 +1,132 @@
+package identifier
+
+import (
+	"strings"
+
+	"gorm.io/gorm/clause"
+)
+
+// sqlOperators maps operators to SQL templates whose first placeholder is the column
+var sqlOperators = map[Operator]string{
+	OpNotEqual:       "? <> ?",
+	OpGreaterThan:    "? > ?",
+	OpGreaterOrEqual: "? >= ?",
+	OpLessThan:       "? < ?",
+	OpLessOrEqual:    "? <= ?",
+	OpIn:             "? IN ?",
+	OpNotIn:          "? NOT IN ?",
+	OpLike:           "? LIKE ?",
+	OpILike:          "? ILIKE ?",
+	OpStartsWith:     "? LIKE ?",
+	OpEndsWith:       "? LIKE ?",
+	OpBetween:        "? BETWEEN ? AND ?",
+	OpIsNull:         "? IS NULL",
+	OpIsNotNull:      "? IS NOT NULL",
+}
+
+// likeEscaper escapes the LIKE wildcards and the escape character itself
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// Expressions renders ANDed conditions as clause expressions for gorm's Where
+func Expressions(conditions []Condition) []clause.Expression {
+	rendered := make([]clause.Expression, 0, len(conditions))
+	for _, c := range conditions {
+		rendered = append(rendered, c.SQL())
+	}
+	return rendered
+}
+
+// SQL renders the condition as a PostgreSQL clause expression
+func (c Condition) SQL() clause.Expression {
+	column := clause.Column{Name: c.Field}
+
+	switch c.Operator {
+	case OpAnd, OpOr:
+		operands := make([]clause.Expression, 0, len(c.Operands))
+		for _, operand := range c.Operands {
+			members := Expressions(operand)
+			if len(members) == 1 {
+				operands = append(operands, members[0])
+				continue
+			}
+			operands = append(operands, sqlGroup{sep: " AND ", operands: members})
+		}
+		return sqlGroup{sep: " " + string(c.Operator) + " ", operands: operands}
+	case OpNot:
+		return sqlGroup{prefix: "NOT ", sep: " AND ", operands: Expressions(c.Operands[0])}
+	case OpEqual:
+		return clause.Eq{Column: column, Value: c.Value}
+	case OpIsNull, OpIsNotNull:
+		return clause.Expr{SQL: sqlOperators[c.Operator], Vars: []interface{}{column}}
+	case OpExists:
+		// a column always exists; a missing value is NULL
+		if c.Value.(bool) {
+			return clause.Expr{SQL: sqlOperators[OpIsNotNull], Vars: []interface{}{column}}
+		}
+		return clause.Expr{SQL: sqlOperators[OpIsNull], Vars: []interface{}{column}}
+	case OpStartsWith:
+		return clause.Expr{SQL: sqlOperators[c.Operator], Vars: []interface{}{column, likeEscaper.Replace(c.Value.(string)) + "%"}}
+	case OpEndsWith:
+		return clause.Expr{SQL: sqlOperators[c.Operator], Vars: []interface{}{column, "%" + likeEscaper.Replace(c.Value.(string))}}
+	case OpRegex:
+		return regexExpression(column, c.Value.(RegexPattern))
+	case OpBetween:
+		bounds := c.Value.([]interface{})
+		return clause.Expr{SQL: sqlOperators[c.Operator], Vars: []interface{}{column, bounds[0], bounds[1]}}
+	default:
+		return clause.Expr{SQL: sqlOperators[c.Operator], Vars: []interface{}{column, c.Value}}
+	}
+}
+
+// regexExpression matches column against a POSIX regular expression. "i" selects the
+// case-insensitive operator; "m", "s" and "x" become embedded options that give the pattern
+// MongoDB's meaning, so without "s" a dot does not match a newline.
+func regexExpression(column clause.Column, regex RegexPattern) clause.Expression {
+	operator := "~"
+	if strings.Contains(regex.Options, "i") {
+		operator = "~*"
+	}
+
+	multiline := strings.Contains(regex.Options, "m")
+	dotAll := strings.Contains(regex.Options, "s")
+
+	var flags string
+	switch {
+	case multiline && dotAll:
+		flags = "w"
+	case multiline:
+		flags = "n"
+	case !dotAll:
+		flags = "p"
+	}
+	if strings.Contains(regex.Options, "x") {
+		flags += "x"
+	}
+
+	pattern := regex.Pattern
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+
+	return clause.Expr{SQL: "? " + operator + " ?", Vars: []interface{}{column, pattern}}
+}
+
+// sqlGroup renders its operands joined by sep inside parentheses, preceded by prefix. Unlike a
+// clause.Expr it is not parenthesized a second time by gorm's WHERE builder.
+type sqlGroup struct {
+	prefix   string
+	sep      string
+	operands []clause.Expression
+}
+
+// Build implements clause.Expression
+func (g sqlGroup) Build(builder clause.Builder) {
+	builder.WriteString(g.prefix + "(")
+	for i, operand := range g.operands {
+		if i > 0 {
+			builder.WriteString(g.sep)
+		}
+		operand.Build(builder)
+	}
+	builder.WriteByte(')')
+}
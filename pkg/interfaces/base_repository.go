@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"iter"
 
 	"github.com/arash-mosavi/go-base-repository/pkg/types"
 )
@@ -12,11 +13,23 @@ type MongoBaseRepository[T types.MongoEntity] interface {
 	FindOneById(ctx context.Context, id types.MongoID) (T, error)
 	FindOne(ctx context.Context, filter types.Identifier) (T, error)
 	FindAll(ctx context.Context, filter types.Identifier) ([]T, error)
+	FindAllOptions(ctx context.Context, filter types.Identifier, opts types.FindOptions) ([]T, error)
 	FindAllWithPagination(ctx context.Context, params types.QueryParams[T]) ([]T, int64, error)
+	FindPage(ctx context.Context, req types.PageRequest) (types.Page[T], error)
+	Stream(ctx context.Context, filter types.Identifier) iter.Seq2[T, error]
 
 	Insert(ctx context.Context, entity T) (T, error)
 	Update(ctx context.Context, filter types.Identifier, entity T) (T, error)
+	// Upsert overwrites the live entity matching filter or inserts entity. A soft-deleted entity
+	// holding the same unique key is neither restored nor overwritten: ErrConflict is returned,
+	// and Restore has to bring it back first.
+	Upsert(ctx context.Context, filter types.Identifier, entity T, opts types.UpsertOptions) (T, error)
+	Patch(ctx context.Context, filter types.Identifier, changes map[string]interface{}) (T, error)
 	Delete(ctx context.Context, filter types.Identifier) error
+	UpdateMany(ctx context.Context, filter types.Identifier, changes map[string]interface{}) (int64, error)
+	DeleteMany(ctx context.Context, filter types.Identifier) (int64, error)
+	FindOneAndUpdate(ctx context.Context, filter types.Identifier, changes map[string]interface{}, opts types.FindAndModifyOptions) (T, error)
+	FindOneAndDelete(ctx context.Context, filter types.Identifier, opts types.FindAndModifyOptions) (T, error)
 
 	// Bulk operations
 	BulkInsert(ctx context.Context, entities []T) ([]T, error)
@@ -36,8 +49,17 @@ type MongoBaseRepository[T types.MongoEntity] interface {
 	RestoreAll(ctx context.Context) error
 
 	// Transaction management
+	WithTransaction(ctx context.Context, fn func(ctx context.Context, repo MongoBaseRepository[T]) error, opts ...*types.TxOptions) error
+
+	// BeginTransaction begins a transaction that every later call on the repository takes part
+	// in until CommitTransaction or RollbackTransaction ends it.
+	//
+	// Deprecated: the transaction belongs to the repository rather than to a context and leaks
+	// into concurrent callers, use WithTransaction
 	BeginTransaction(ctx context.Context) error
+	// Deprecated: use WithTransaction
 	CommitTransaction(ctx context.Context) error
+	// Deprecated: use WithTransaction
 	RollbackTransaction(ctx context.Context) error
 }
 
@@ -47,11 +69,23 @@ type PostgresBaseRepository[T types.PostgresEntity] interface {
 	FindOneById(ctx context.Context, id types.PostgresID) (T, error)
 	FindOne(ctx context.Context, filter types.Identifier) (T, error)
 	FindAll(ctx context.Context, filter types.Identifier) ([]T, error)
+	FindAllOptions(ctx context.Context, filter types.Identifier, opts types.FindOptions) ([]T, error)
 	FindAllWithPagination(ctx context.Context, params types.QueryParams[T]) ([]T, int64, error)
+	FindPage(ctx context.Context, req types.PageRequest) (types.Page[T], error)
+	Stream(ctx context.Context, filter types.Identifier) iter.Seq2[T, error]
 
 	Insert(ctx context.Context, entity T) (T, error)
 	Update(ctx context.Context, filter types.Identifier, entity T) (T, error)
+	// Upsert overwrites the live entity matching filter or inserts entity. A soft-deleted entity
+	// holding the same unique key is neither restored nor overwritten: ErrConflict is returned,
+	// and Restore has to bring it back first.
+	Upsert(ctx context.Context, filter types.Identifier, entity T, opts types.UpsertOptions) (T, error)
+	Patch(ctx context.Context, filter types.Identifier, changes map[string]interface{}) (T, error)
 	Delete(ctx context.Context, filter types.Identifier) error
+	UpdateMany(ctx context.Context, filter types.Identifier, changes map[string]interface{}) (int64, error)
+	DeleteMany(ctx context.Context, filter types.Identifier) (int64, error)
+	FindOneAndUpdate(ctx context.Context, filter types.Identifier, changes map[string]interface{}, opts types.FindAndModifyOptions) (T, error)
+	FindOneAndDelete(ctx context.Context, filter types.Identifier, opts types.FindAndModifyOptions) (T, error)
 
 	// Bulk operations
 	BulkInsert(ctx context.Context, entities []T) ([]T, error)
@@ -71,7 +105,16 @@ type PostgresBaseRepository[T types.PostgresEntity] interface {
 	RestoreAll(ctx context.Context) error
 
 	// Transaction management
+	WithTransaction(ctx context.Context, fn func(ctx context.Context, repo PostgresBaseRepository[T]) error, opts ...*types.TxOptions) error
+
+	// BeginTransaction begins a transaction that every later call on the repository takes part
+	// in until CommitTransaction or RollbackTransaction ends it.
+	//
+	// Deprecated: the transaction belongs to the repository rather than to a context and leaks
+	// into concurrent callers, use WithTransaction
 	BeginTransaction(ctx context.Context) error
+	// Deprecated: use WithTransaction
 	CommitTransaction(ctx context.Context) error
+	// Deprecated: use WithTransaction
 	RollbackTransaction(ctx context.Context) error
 }
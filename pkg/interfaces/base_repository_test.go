@@ -3,6 +3,7 @@ package interfaces_test
 import (
 	"context"
 	"errors"
+	"iter"
 	"testing"
 	"time"
 
@@ -96,6 +97,19 @@ func (m *MockMongoRepository) FindAll(ctx context.Context, filter types.Identifi
 	return entities, nil
 }
 
+func (m *MockMongoRepository) FindAllOptions(ctx context.Context, filter types.Identifier, opts types.FindOptions) ([]*MockMongoEntity, error) {
+	entities, err := m.FindAll(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Limit > 0 && opts.Limit < len(entities) {
+		entities = entities[:opts.Limit]
+	}
+
+	return entities, nil
+}
+
 func (m *MockMongoRepository) FindAllWithPagination(ctx context.Context, params types.QueryParams[*MockMongoEntity]) ([]*MockMongoEntity, int64, error) {
 	entities, err := m.FindAll(ctx, nil)
 	if err != nil {
@@ -104,6 +118,29 @@ func (m *MockMongoRepository) FindAllWithPagination(ctx context.Context, params
 	return entities, int64(len(entities)), nil
 }
 
+func (m *MockMongoRepository) FindPage(ctx context.Context, req types.PageRequest) (types.Page[*MockMongoEntity], error) {
+	entities, err := m.FindAllOptions(ctx, req.Where, types.FindOptions{Limit: req.PageSize()})
+	if err != nil {
+		return types.Page[*MockMongoEntity]{}, err
+	}
+	return types.Page[*MockMongoEntity]{Items: entities}, nil
+}
+
+func (m *MockMongoRepository) Stream(ctx context.Context, filter types.Identifier) iter.Seq2[*MockMongoEntity, error] {
+	return func(yield func(*MockMongoEntity, error) bool) {
+		entities, err := m.FindAll(ctx, filter)
+		if err != nil {
+			yield(nil, err)
+			return
+		}
+		for _, entity := range entities {
+			if !yield(entity, nil) {
+				return
+			}
+		}
+	}
+}
+
 func (m *MockMongoRepository) Insert(ctx context.Context, entity *MockMongoEntity) (*MockMongoEntity, error) {
 	if entity.ID == primitive.NilObjectID {
 		entity.ID = primitive.NewObjectID()
@@ -120,6 +157,25 @@ func (m *MockMongoRepository) Update(ctx context.Context, filter types.Identifie
 	return entity, nil
 }
 
+func (m *MockMongoRepository) Upsert(ctx context.Context, filter types.Identifier, entity *MockMongoEntity, opts types.UpsertOptions) (*MockMongoEntity, error) {
+	if _, exists := m.entities[entity.ID]; !exists {
+		return m.Insert(ctx, entity)
+	}
+	return m.Update(ctx, filter, entity)
+}
+
+func (m *MockMongoRepository) Patch(ctx context.Context, filter types.Identifier, changes map[string]interface{}) (*MockMongoEntity, error) {
+	entity, err := m.FindOne(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if name, ok := changes["name"].(string); ok {
+		entity.Name = name
+	}
+	entity.UpdatedAt = time.Now()
+	return entity, nil
+}
+
 func (m *MockMongoRepository) Delete(ctx context.Context, filter types.Identifier) error {
 	// Simple mock implementation - delete first entity
 	for id := range m.entities {
@@ -129,6 +185,46 @@ func (m *MockMongoRepository) Delete(ctx context.Context, filter types.Identifie
 	return errors.New("entity not found")
 }
 
+func (m *MockMongoRepository) UpdateMany(ctx context.Context, filter types.Identifier, changes map[string]interface{}) (int64, error) {
+	for _, entity := range m.entities {
+		if name, ok := changes["name"].(string); ok {
+			entity.Name = name
+		}
+		entity.UpdatedAt = time.Now()
+	}
+	return int64(len(m.entities)), nil
+}
+
+func (m *MockMongoRepository) DeleteMany(ctx context.Context, filter types.Identifier) (int64, error) {
+	deleted := int64(len(m.entities))
+	m.entities = make(map[primitive.ObjectID]*MockMongoEntity)
+	return deleted, nil
+}
+
+func (m *MockMongoRepository) FindOneAndUpdate(ctx context.Context, filter types.Identifier, changes map[string]interface{}, opts types.FindAndModifyOptions) (*MockMongoEntity, error) {
+	entity, err := m.FindOne(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	before := *entity
+	if _, err := m.Patch(ctx, filter, changes); err != nil {
+		return nil, err
+	}
+	if opts.Return == types.ReturnBefore {
+		return &before, nil
+	}
+	return entity, nil
+}
+
+func (m *MockMongoRepository) FindOneAndDelete(ctx context.Context, filter types.Identifier, opts types.FindAndModifyOptions) (*MockMongoEntity, error) {
+	entity, err := m.FindOne(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	delete(m.entities, entity.ID)
+	return entity, nil
+}
+
 func (m *MockMongoRepository) BulkInsert(ctx context.Context, entities []*MockMongoEntity) ([]*MockMongoEntity, error) {
 	for _, entity := range entities {
 		if entity.ID == primitive.NilObjectID {
@@ -224,6 +320,10 @@ func (m *MockMongoRepository) RestoreAll(ctx context.Context) error {
 	return nil
 }
 
+func (m *MockMongoRepository) WithTransaction(ctx context.Context, fn func(ctx context.Context, repo interfaces.MongoBaseRepository[*MockMongoEntity]) error, opts ...*types.TxOptions) error {
+	return fn(ctx, m) // Mock implementation
+}
+
 func (m *MockMongoRepository) BeginTransaction(ctx context.Context) error {
 	return nil // Mock implementation
 }
@@ -308,6 +408,97 @@ func TestMongoBaseRepository_CRUD(t *testing.T) {
 	}
 }
 
+func TestMongoBaseRepository_Upsert(t *testing.T) {
+	repo := NewMockMongoRepository()
+	ctx := context.Background()
+	filter := identifier.New().Equal("email", "upsert@example.com")
+
+	entity := &MockMongoEntity{Name: "Inserted", Email: "upsert@example.com"}
+	inserted, err := repo.Upsert(ctx, filter, entity, types.UpsertOptions{})
+	if err != nil {
+		t.Fatalf("Failed to upsert entity: %v", err)
+	}
+	if inserted.ID == primitive.NilObjectID {
+		t.Error("Entity ID should be set after upsert")
+	}
+
+	inserted.Name = "Replaced"
+	replaced, err := repo.Upsert(ctx, filter, inserted, types.UpsertOptions{UpdateFields: []string{"name"}})
+	if err != nil {
+		t.Fatalf("Failed to upsert entity: %v", err)
+	}
+	if replaced.ID != inserted.ID {
+		t.Errorf("Expected ID %s, got %s", inserted.ID.Hex(), replaced.ID.Hex())
+	}
+
+	all, err := repo.FindAll(ctx, filter)
+	if err != nil {
+		t.Fatalf("Failed to find all entities: %v", err)
+	}
+	if len(all) != 1 {
+		t.Errorf("Expected 1 entity, got %d", len(all))
+	}
+}
+
+func TestMongoBaseRepository_FindAllOptions(t *testing.T) {
+	repo := NewMockMongoRepository()
+	ctx := context.Background()
+
+	entities := []*MockMongoEntity{
+		{Name: "Entity 1"},
+		{Name: "Entity 2"},
+		{Name: "Entity 3"},
+	}
+	if _, err := repo.BulkInsert(ctx, entities); err != nil {
+		t.Fatalf("Failed to bulk insert entities: %v", err)
+	}
+
+	limited, err := repo.FindAllOptions(ctx, identifier.NewMongoIdentifier(), types.FindOptions{
+		Sort:       types.SortMap{"name": types.SortAsc},
+		Limit:      2,
+		Projection: []string{"name"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to find entities with options: %v", err)
+	}
+
+	if len(limited) != 2 {
+		t.Errorf("Expected 2 entities, got %d", len(limited))
+	}
+}
+
+func TestMongoBaseRepository_Stream(t *testing.T) {
+	repo := NewMockMongoRepository()
+	ctx := context.Background()
+
+	entities := []*MockMongoEntity{
+		{Name: "Entity 1"},
+		{Name: "Entity 2"},
+		{Name: "Entity 3"},
+	}
+	if _, err := repo.BulkInsert(ctx, entities); err != nil {
+		t.Fatalf("Failed to bulk insert entities: %v", err)
+	}
+
+	count := 0
+	for entity, err := range repo.Stream(ctx, identifier.NewMongoIdentifier()) {
+		if err != nil {
+			t.Fatalf("Failed to stream entities: %v", err)
+		}
+		if entity == nil {
+			t.Fatal("Expected entity, got nil")
+		}
+		count++
+		if count == 2 {
+			break
+		}
+	}
+
+	if count != 2 {
+		t.Errorf("Expected to stop after 2 entities, got %d", count)
+	}
+}
+
 func TestMongoBaseRepository_BulkOperations(t *testing.T) {
 	repo := NewMockMongoRepository()
 	ctx := context.Background()
@@ -342,6 +533,63 @@ func TestMongoBaseRepository_BulkOperations(t *testing.T) {
 	}
 }
 
+func TestMongoBaseRepository_ManyOperations(t *testing.T) {
+	repo := NewMockMongoRepository()
+	ctx := context.Background()
+
+	for _, name := range []string{"first", "second", "third"} {
+		if _, err := repo.Insert(ctx, &MockMongoEntity{Name: name}); err != nil {
+			t.Fatalf("Failed to insert entity: %v", err)
+		}
+	}
+
+	updated, err := repo.UpdateMany(ctx, identifier.New(), types.NewUpdateSet().Set("name", "renamed"))
+	if err != nil {
+		t.Fatalf("Failed to update many: %v", err)
+	}
+	if updated != 3 {
+		t.Errorf("Expected 3 updated entities, got %d", updated)
+	}
+
+	deleted, err := repo.DeleteMany(ctx, identifier.New())
+	if err != nil {
+		t.Fatalf("Failed to delete many: %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("Expected 3 deleted entities, got %d", deleted)
+	}
+}
+
+func TestMongoBaseRepository_FindAndModify(t *testing.T) {
+	repo := NewMockMongoRepository()
+	ctx := context.Background()
+
+	if _, err := repo.Insert(ctx, &MockMongoEntity{Name: "queued"}); err != nil {
+		t.Fatalf("Failed to insert entity: %v", err)
+	}
+
+	before, err := repo.FindOneAndUpdate(ctx, identifier.New(), types.NewUpdateSet().Set("name", "claimed"),
+		types.FindAndModifyOptions{Return: types.ReturnBefore})
+	if err != nil {
+		t.Fatalf("Failed to find and update: %v", err)
+	}
+	if before.Name != "queued" {
+		t.Errorf("Expected name 'queued', got '%s'", before.Name)
+	}
+
+	deleted, err := repo.FindOneAndDelete(ctx, identifier.New(), types.FindAndModifyOptions{})
+	if err != nil {
+		t.Fatalf("Failed to find and delete: %v", err)
+	}
+	if deleted.Name != "claimed" {
+		t.Errorf("Expected name 'claimed', got '%s'", deleted.Name)
+	}
+
+	if _, err := repo.FindOneAndDelete(ctx, identifier.New(), types.FindAndModifyOptions{}); err == nil {
+		t.Error("Expected an error when no entity is left")
+	}
+}
+
 func TestMongoBaseRepository_TransactionHandling(t *testing.T) {
 	repo := NewMockMongoRepository()
 	ctx := context.Background()
@@ -367,3 +615,28 @@ func TestMongoBaseRepository_TransactionHandling(t *testing.T) {
 		t.Fatalf("Failed to rollback transaction: %v", err)
 	}
 }
+
+func TestMongoBaseRepository_WithTransaction(t *testing.T) {
+	repo := NewMockMongoRepository()
+	ctx := context.Background()
+
+	err := repo.WithTransaction(ctx, func(ctx context.Context, txRepo interfaces.MongoBaseRepository[*MockMongoEntity]) error {
+		_, err := txRepo.Insert(ctx, &MockMongoEntity{Name: "Tx Entity", Email: "tx@example.com", Slug: "tx-entity"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Failed to run transaction: %v", err)
+	}
+
+	if len(repo.entities) != 1 {
+		t.Errorf("Expected 1 entity, got %d", len(repo.entities))
+	}
+
+	errAbort := errors.New("abort")
+	err = repo.WithTransaction(ctx, func(ctx context.Context, txRepo interfaces.MongoBaseRepository[*MockMongoEntity]) error {
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Errorf("Expected transaction error to be returned, got %v", err)
+	}
+}
@@ -2,215 +2,635 @@ package mongo
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
 	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
 	"github.com/arash-mosavi/go-base-repository/pkg/interfaces"
+	repoSchema "github.com/arash-mosavi/go-base-repository/pkg/schema"
 	"github.com/arash-mosavi/go-base-repository/pkg/types"
-	mongoDomain "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/domain"
-	mongoIdentifier "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/identifier"
-	mongoUOW "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // BaseRepository implements the MongoDB base repository using composition
 type BaseRepository[T types.MongoEntity] struct {
-	factory mongoUOW.IUnitOfWorkFactory[T]
+	client     *mongoDriver.Client
+	collection *mongoDriver.Collection
+	fields     *repoSchema.Schema
+	settings   settings
+	manual     *manualSession
 }
 
-// NewBaseRepository creates a new MongoDB base repository
-func NewBaseRepository[T types.MongoEntity](factory mongoUOW.IUnitOfWorkFactory[T]) interfaces.MongoBaseRepository[T] {
+// NewBaseRepository creates a new MongoDB base repository backed by the entity's collection in database
+func NewBaseRepository[T types.MongoEntity](database *mongoDriver.Database, opts ...Option) interfaces.MongoBaseRepository[T] {
 	return &BaseRepository[T]{
-		factory: factory,
+		client:     database.Client(),
+		collection: database.Collection(collectionName[T]()),
+		fields:     repoSchema.FromBSON(reflect.TypeOf((*T)(nil)).Elem()),
+		settings:   newSettings(opts),
+		manual:     &manualSession{},
 	}
 }
 
 // FindOneById finds an entity by its MongoDB ObjectID
 func (r *BaseRepository[T]) FindOneById(ctx context.Context, id types.MongoID) (T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.FindOneById(ctx, id)
+	var zero T
+
+	filter := bson.M{
+		"_id":       id,
+		"deletedAt": bson.M{"$exists": false},
+	}
+
+	var result T
+	if err := r.collection.FindOne(r.bind(ctx), filter).Decode(&result); err != nil {
+		return zero, fmt.Errorf("failed to find by id: %w", translateError(err))
+	}
+
+	return result, nil
 }
 
 // FindOne finds a single entity using identifier
 func (r *BaseRepository[T]) FindOne(ctx context.Context, filter types.Identifier) (T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-	return uow.FindOneByIdentifier(ctx, unifiedFilter.GetMongoIdentifier())
+	var zero T
+
+	query, err := r.toBSON(filter)
+	if err != nil {
+		return zero, fmt.Errorf("failed to find by identifier: %w", err)
+	}
+	var result T
+	if err := r.collection.FindOne(r.bind(ctx), excludeDeleted(query)).Decode(&result); err != nil {
+		return zero, fmt.Errorf("failed to find by identifier: %w", translateError(err))
+	}
+
+	return result, nil
 }
 
 // FindAll finds all entities matching the identifier
 func (r *BaseRepository[T]) FindAll(ctx context.Context, filter types.Identifier) ([]T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.FindAll(ctx)
+	query, err := r.toBSON(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find all: %w", err)
+	}
+
+	return r.find(ctx, excludeDeleted(query))
+}
+
+// FindAllOptions finds the entities matching the identifier, sorted, limited and projected as opts asks
+func (r *BaseRepository[T]) FindAllOptions(ctx context.Context, filter types.Identifier, opts types.FindOptions) ([]T, error) {
+	query, err := r.toBSON(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find all: %w", err)
+	}
+
+	sort, err := r.sortDocument(opts.Ordering())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find all: %w", err)
+	}
+	projection, err := r.fields.Fields(opts.Projection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find all: %w", err)
+	}
+
+	findOptions := options.Find()
+	if len(sort) > 0 {
+		findOptions.SetSort(sort)
+	}
+	if opts.Limit > 0 {
+		findOptions.SetLimit(int64(opts.Limit))
+	}
+	if len(projection) > 0 {
+		document := bson.D{}
+		for _, field := range projection {
+			document = append(document, bson.E{Key: field, Value: 1})
+		}
+		findOptions.SetProjection(document)
+	}
+
+	return r.find(ctx, excludeDeleted(query), findOptions)
 }
 
 // FindAllWithPagination finds entities with pagination
 func (r *BaseRepository[T]) FindAllWithPagination(ctx context.Context, params types.QueryParams[T]) ([]T, int64, error) {
-	uow := r.factory.CreateWithContext(ctx)
-
-	queryParams := mongoDomain.QueryParams[T]{
-		Filter: params.Filter,
-		Limit:  params.Limit,
-		Offset: params.Offset,
-		Sort:   convertSortMap(params.Sort),
+	filter, err := r.paginationFilter(params)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	entities, count, err := uow.FindAllWithPagination(ctx, queryParams)
-	return entities, int64(count), err
+	return r.findWithPagination(ctx, excludeDeleted(filter), params)
 }
 
 // Insert creates a new entity
 func (r *BaseRepository[T]) Insert(ctx context.Context, entity T) (T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.Insert(ctx, entity)
+	now := time.Now()
+	setCreatedAt(entity, now)
+	setUpdatedAt(entity, now)
+
+	if entity.GetID().IsZero() {
+		entity.SetID(primitive.NewObjectID())
+	}
+
+	if _, err := r.collection.InsertOne(r.bind(ctx), entity); err != nil {
+		return entity, fmt.Errorf("failed to insert: %w", translateError(err))
+	}
+
+	return entity, nil
 }
 
-// Update modifies an existing entity
+// Update modifies an existing entity. The version of Versioned entities must match the stored one
+// and is incremented.
 func (r *BaseRepository[T]) Update(ctx context.Context, filter types.Identifier, entity T) (T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-	return uow.Update(ctx, unifiedFilter.GetMongoIdentifier(), entity)
+	query, err := r.requiredBSON(filter)
+	if err != nil {
+		return entity, fmt.Errorf("failed to update: %w", err)
+	}
+	query["deletedAt"] = bson.M{"$exists": false}
+
+	versioned, isVersioned := interface{}(entity).(types.Versioned)
+	var version string
+	var expected int64
+	if isVersioned {
+		if version, err = r.fields.Version(); err != nil {
+			return entity, fmt.Errorf("failed to update: %w", err)
+		}
+		expected = versioned.GetVersion()
+		query[version] = matchVersion(expected)
+		versioned.SetVersion(expected + 1)
+	}
+
+	setUpdatedAt(entity, time.Now())
+
+	result := r.collection.FindOneAndUpdate(
+		r.bind(ctx),
+		query,
+		bson.M{"$set": entity},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	var updated T
+	if err := result.Decode(&updated); err != nil {
+		if isVersioned {
+			versioned.SetVersion(expected)
+			err = r.versionConflict(ctx, query, version, err)
+		}
+		return entity, fmt.Errorf("failed to update: %w", translateError(err))
+	}
+
+	return updated, nil
 }
 
 // Delete removes an entity
 func (r *BaseRepository[T]) Delete(ctx context.Context, filter types.Identifier) error {
-	uow := r.factory.CreateWithContext(ctx)
-	unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-	return uow.Delete(ctx, unifiedFilter.GetMongoIdentifier())
+	query, err := r.requiredBSON(filter)
+	if err != nil {
+		return fmt.Errorf("failed to delete: %w", err)
+	}
+
+	result, err := r.collection.DeleteOne(r.bind(ctx), query)
+	if err != nil {
+		return fmt.Errorf("failed to delete: %w", translateError(err))
+	}
+
+	if result.DeletedCount == 0 {
+		return repoErrors.ErrNotFound
+	}
+
+	return nil
 }
 
 // BulkInsert creates multiple entities
 func (r *BaseRepository[T]) BulkInsert(ctx context.Context, entities []T) ([]T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.BulkInsert(ctx, entities)
+	if len(entities) == 0 {
+		return entities, nil
+	}
+
+	now := time.Now()
+	documents := make([]interface{}, len(entities))
+	for i, entity := range entities {
+		setCreatedAt(entity, now)
+		setUpdatedAt(entity, now)
+
+		if entity.GetID().IsZero() {
+			entity.SetID(primitive.NewObjectID())
+		}
+
+		documents[i] = entity
+	}
+
+	if _, err := r.collection.InsertMany(r.bind(ctx), documents); err != nil {
+		return nil, fmt.Errorf("failed to bulk insert: %w", translateError(err))
+	}
+
+	return entities, nil
 }
 
 // BulkUpdate modifies multiple entities
 func (r *BaseRepository[T]) BulkUpdate(ctx context.Context, entities []T) ([]T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.BulkUpdate(ctx, entities)
+	if len(entities) == 0 {
+		return entities, nil
+	}
+
+	now := time.Now()
+	models := make([]mongoDriver.WriteModel, 0, len(entities))
+	for _, entity := range entities {
+		setUpdatedAt(entity, now)
+
+		filter := bson.M{
+			"_id":       entity.GetID(),
+			"deletedAt": bson.M{"$exists": false},
+		}
+		models = append(models, mongoDriver.NewUpdateOneModel().SetFilter(filter).SetUpdate(bson.M{"$set": entity}))
+	}
+
+	result, err := r.collection.BulkWrite(r.bind(ctx), models, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to bulk update: %w", translateError(err))
+	}
+
+	if result.MatchedCount != int64(len(entities)) {
+		return entities, fmt.Errorf("not all entities were updated: matched %d out of %d: %w", result.MatchedCount, len(entities), repoErrors.ErrNotFound)
+	}
+
+	return entities, nil
 }
 
 // BulkDelete removes multiple entities
 func (r *BaseRepository[T]) BulkDelete(ctx context.Context, filters []types.Identifier) error {
-	uow := r.factory.CreateWithContext(ctx)
+	return r.BulkHardDelete(ctx, filters)
+}
 
-	// Convert unified filters to native MongoDB identifiers
-	mongoFilters := make([]mongoIdentifier.IIdentifier, len(filters))
-	for i, filter := range filters {
-		unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-		mongoFilters[i] = unifiedFilter.GetMongoIdentifier()
+// SoftDelete marks an entity as deleted. For Versioned entities, an equality condition on the
+// version in filter is the version the document is expected to have rather than part of the
+// match, and ErrConflict is returned when the stored one differs. The version is incremented.
+func (r *BaseRepository[T]) SoftDelete(ctx context.Context, filter types.Identifier) (T, error) {
+	var zero T
+
+	conditions, err := r.conditions(filter)
+	if err != nil {
+		return zero, fmt.Errorf("failed to soft delete: %w", err)
 	}
 
-	return uow.BulkHardDelete(ctx, mongoFilters)
-}
+	var version string
+	var expected int64
+	var checked bool
+	if r.versioned() {
+		if version, err = r.fields.Version(); err != nil {
+			return zero, fmt.Errorf("failed to soft delete: %w", err)
+		}
+		if conditions, expected, checked, err = r.fields.ExpectedVersionCondition(conditions); err != nil {
+			return zero, fmt.Errorf("failed to soft delete: %w", err)
+		}
+	}
+	if err := requireConditions(conditions); err != nil {
+		return zero, fmt.Errorf("failed to soft delete: %w", err)
+	}
 
-// SoftDelete marks an entity as deleted
-func (r *BaseRepository[T]) SoftDelete(ctx context.Context, filter types.Identifier) (T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-	return uow.SoftDelete(ctx, unifiedFilter.GetMongoIdentifier())
+	query := identifier.Document(conditions)
+	query["deletedAt"] = bson.M{"$exists": false}
+	if checked {
+		query[version] = matchVersion(expected)
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"deletedAt": now,
+			"updatedAt": now,
+		},
+	}
+	if version != "" {
+		update["$inc"] = bson.M{version: 1}
+	}
+
+	result := r.collection.FindOneAndUpdate(
+		r.bind(ctx),
+		query,
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	var updated T
+	if err := result.Decode(&updated); err != nil {
+		if checked {
+			err = r.versionConflict(ctx, query, version, err)
+		}
+		return zero, fmt.Errorf("failed to soft delete: %w", translateError(err))
+	}
+
+	return updated, nil
 }
 
 // HardDelete permanently removes an entity
 func (r *BaseRepository[T]) HardDelete(ctx context.Context, filter types.Identifier) (T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-	return uow.HardDelete(ctx, unifiedFilter.GetMongoIdentifier())
+	var zero T
+
+	query, err := r.requiredBSON(filter)
+	if err != nil {
+		return zero, fmt.Errorf("failed to hard delete: %w", err)
+	}
+
+	var deleted T
+	if err := r.collection.FindOneAndDelete(r.bind(ctx), query).Decode(&deleted); err != nil {
+		return zero, fmt.Errorf("failed to hard delete: %w", translateError(err))
+	}
+
+	return deleted, nil
 }
 
 // BulkSoftDelete marks multiple entities as deleted
 func (r *BaseRepository[T]) BulkSoftDelete(ctx context.Context, filters []types.Identifier) error {
-	uow := r.factory.CreateWithContext(ctx)
+	if len(filters) == 0 {
+		return nil
+	}
 
-	mongoFilters := make([]mongoIdentifier.IIdentifier, len(filters))
-	for i, filter := range filters {
-		unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-		mongoFilters[i] = unifiedFilter.GetMongoIdentifier()
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"deletedAt": now,
+			"updatedAt": now,
+		},
+	}
+	if r.versioned() {
+		version, err := r.fields.Version()
+		if err != nil {
+			return fmt.Errorf("failed to bulk soft delete: %w", err)
+		}
+		update["$inc"] = bson.M{version: 1}
 	}
 
-	return uow.BulkSoftDelete(ctx, mongoFilters)
+	models := make([]mongoDriver.WriteModel, 0, len(filters))
+	for _, filter := range filters {
+		query, err := r.requiredBSON(filter)
+		if err != nil {
+			return fmt.Errorf("failed to bulk soft delete: %w", err)
+		}
+		query["deletedAt"] = bson.M{"$exists": false}
+		models = append(models, mongoDriver.NewUpdateOneModel().SetFilter(query).SetUpdate(update))
+	}
+
+	if _, err := r.collection.BulkWrite(r.bind(ctx), models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("failed to bulk soft delete: %w", translateError(err))
+	}
+
+	return nil
 }
 
 // BulkHardDelete permanently removes multiple entities
 func (r *BaseRepository[T]) BulkHardDelete(ctx context.Context, filters []types.Identifier) error {
-	uow := r.factory.CreateWithContext(ctx)
+	if len(filters) == 0 {
+		return nil
+	}
+
+	models := make([]mongoDriver.WriteModel, 0, len(filters))
+	for _, filter := range filters {
+		query, err := r.requiredBSON(filter)
+		if err != nil {
+			return fmt.Errorf("failed to bulk hard delete: %w", err)
+		}
+		models = append(models, mongoDriver.NewDeleteOneModel().SetFilter(query))
+	}
 
-	mongoFilters := make([]mongoIdentifier.IIdentifier, len(filters))
-	for i, filter := range filters {
-		unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-		mongoFilters[i] = unifiedFilter.GetMongoIdentifier()
+	if _, err := r.collection.BulkWrite(r.bind(ctx), models, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("failed to bulk hard delete: %w", translateError(err))
 	}
 
-	return uow.BulkHardDelete(ctx, mongoFilters)
+	return nil
 }
 
 // GetTrashed retrieves all soft-deleted entities
 func (r *BaseRepository[T]) GetTrashed(ctx context.Context) ([]T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.GetTrashed(ctx)
+	return r.find(ctx, bson.M{"deletedAt": bson.M{"$exists": true}})
 }
 
 // GetTrashedWithPagination retrieves soft-deleted entities with pagination
 func (r *BaseRepository[T]) GetTrashedWithPagination(ctx context.Context, params types.QueryParams[T]) ([]T, int64, error) {
-	uow := r.factory.CreateWithContext(ctx)
-
-	queryParams := mongoDomain.QueryParams[T]{
-		Filter: params.Filter,
-		Limit:  params.Limit,
-		Offset: params.Offset,
-		Sort:   convertSortMap(params.Sort),
+	filter, err := r.paginationFilter(params)
+	if err != nil {
+		return nil, 0, err
 	}
+	identifier.MergeCondition(filter, "deletedAt", bson.M{"$exists": true})
 
-	entities, count, err := uow.GetTrashedWithPagination(ctx, queryParams)
-	return entities, int64(count), err
+	return r.findWithPagination(ctx, filter, params)
 }
 
 // Restore recovers a soft-deleted entity
 func (r *BaseRepository[T]) Restore(ctx context.Context, filter types.Identifier) (T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-	return uow.Restore(ctx, unifiedFilter.GetMongoIdentifier())
+	var zero T
+
+	query, err := r.toBSON(filter)
+	if err != nil {
+		return zero, fmt.Errorf("failed to restore: %w", err)
+	}
+	query["deletedAt"] = bson.M{"$exists": true}
+
+	update := bson.M{
+		"$unset": bson.M{"deletedAt": ""},
+		"$set":   bson.M{"updatedAt": time.Now()},
+	}
+
+	result := r.collection.FindOneAndUpdate(
+		r.bind(ctx),
+		query,
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	var restored T
+	if err := result.Decode(&restored); err != nil {
+		return zero, fmt.Errorf("failed to restore: %w", translateError(err))
+	}
+
+	return restored, nil
 }
 
 // RestoreAll recovers all soft-deleted entities
 func (r *BaseRepository[T]) RestoreAll(ctx context.Context) error {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.RestoreAll(ctx)
+	filter := bson.M{"deletedAt": bson.M{"$exists": true}}
+	update := bson.M{
+		"$unset": bson.M{"deletedAt": ""},
+		"$set":   bson.M{"updatedAt": time.Now()},
+	}
+
+	if _, err := r.collection.UpdateMany(r.bind(ctx), filter, update); err != nil {
+		return fmt.Errorf("failed to restore all: %w", translateError(err))
+	}
+
+	return nil
 }
 
-// BeginTransaction starts a database transaction
-func (r *BaseRepository[T]) BeginTransaction(ctx context.Context) error {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.BeginTransaction(ctx)
+// find runs filter against the collection and decodes every match
+func (r *BaseRepository[T]) find(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]T, error) {
+	cursor, err := r.collection.Find(r.bind(ctx), filter, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find: %w", translateError(err))
+	}
+	defer cursor.Close(ctx)
+
+	var results []T
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode results: %w", translateError(err))
+	}
+
+	return results, nil
 }
 
-// CommitTransaction commits the current transaction
-func (r *BaseRepository[T]) CommitTransaction(ctx context.Context) error {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.CommitTransaction(ctx)
+// findWithPagination counts the documents matching filter and returns the requested page of them
+func (r *BaseRepository[T]) findWithPagination(ctx context.Context, filter bson.M, params types.QueryParams[T]) ([]T, int64, error) {
+	sort, err := r.sortDocument(params.Ordering())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find with pagination: %w", err)
+	}
+
+	total, err := r.collection.CountDocuments(r.bind(ctx), filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count documents: %w", translateError(err))
+	}
+
+	opts := options.Find()
+	if params.Limit > 0 {
+		opts.SetLimit(int64(params.Limit))
+	}
+	if params.Offset > 0 {
+		opts.SetSkip(int64(params.Offset))
+	}
+	if len(sort) > 0 {
+		opts.SetSort(sort)
+	}
+
+	entities, err := r.find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entities, total, nil
 }
 
-// RollbackTransaction rolls back the current transaction
-func (r *BaseRepository[T]) RollbackTransaction(ctx context.Context) error {
-	uow := r.factory.CreateWithContext(ctx)
-	uow.RollbackTransaction(ctx)
-	return nil
+// paginationFilter combines the Where conditions and the Filter model of params into one filter document
+func (r *BaseRepository[T]) paginationFilter(params types.QueryParams[T]) (bson.M, error) {
+	filter, err := r.toBSON(params.Where)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find with pagination: %w", err)
+	}
+
+	for k, v := range filterFromModel(params.Filter) {
+		identifier.MergeCondition(filter, k, v)
+	}
+
+	return filter, nil
 }
 
-// convertSortMap converts unified sort map to MongoDB sort map
-func convertSortMap(sort types.SortMap) mongoDomain.SortMap {
-	if sort == nil {
-		return nil
+// excludeDeleted restricts query to documents that are not soft-deleted, unless it already filters on deletedAt
+func excludeDeleted(query bson.M) bson.M {
+	if _, ok := query["deletedAt"]; !ok {
+		query["deletedAt"] = bson.M{"$exists": false}
 	}
+	return query
+}
+
+// sortDocument resolves the fields of sort against T and converts it to a MongoDB sort document
+func (r *BaseRepository[T]) sortDocument(sort types.SortSpec) (bson.D, error) {
+	resolved, err := r.fields.Sort(sort)
+	if err != nil {
+		return nil, err
+	}
+	return convertSortMap(resolved)
+}
 
-	mongoSort := make(mongoDomain.SortMap)
-	for field, direction := range sort {
-		switch direction {
+// convertSortMap converts a sort specification to a MongoDB sort document. MongoDB always
+// orders missing and null values first when ascending and last when descending, so any
+// other NULLS placement is reported as ErrInvalidFilter.
+func convertSortMap(sort types.SortSpec) (bson.D, error) {
+	if len(sort) == 0 {
+		return nil, nil
+	}
+
+	mongoSort := make(bson.D, 0, len(sort))
+	for _, field := range sort {
+		if field.Field == "" {
+			return nil, fmt.Errorf("%w: sort field without a name", repoErrors.ErrInvalidFilter)
+		}
+
+		var order int
+		var natural types.NullsOrder
+		switch field.Direction {
 		case types.SortAsc:
-			mongoSort[field] = mongoDomain.SortAsc
+			order, natural = 1, types.NullsFirst
 		case types.SortDesc:
-			mongoSort[field] = mongoDomain.SortDesc
+			order, natural = -1, types.NullsLast
+		default:
+			return nil, fmt.Errorf("%w: unknown sort direction %q for field %q", repoErrors.ErrInvalidFilter, field.Direction, field.Field)
+		}
+
+		if field.Nulls != types.NullsDefault && field.Nulls != natural {
+			return nil, fmt.Errorf("%w: MongoDB cannot sort nulls %s on field %q in %s order", repoErrors.ErrInvalidFilter, field.Nulls, field.Field, field.Direction)
+		}
+
+		mongoSort = append(mongoSort, bson.E{Key: field.Field, Value: order})
+	}
+
+	return mongoSort, nil
+}
+
+// collectionName returns the collection used for T, matching the unit of work naming convention
+func collectionName[T types.MongoEntity]() string {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return strings.ToLower(t.Name()) + "s"
+}
+
+// filterFromModel builds an equality filter from the non-zero fields of model
+func filterFromModel(model interface{}) bson.M {
+	filter := bson.M{}
+
+	v := reflect.ValueOf(model)
+	if !v.IsValid() || (v.Kind() == reflect.Ptr && v.IsNil()) {
+		return filter
+	}
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return filter
+	}
+
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		fieldType := t.Field(i)
+
+		if !field.CanInterface() || field.IsZero() {
+			continue
+		}
+
+		fieldName := fieldType.Name
+		if tag := fieldType.Tag.Get("bson"); tag != "" && tag != "-" {
+			fieldName = strings.Split(tag, ",")[0]
 		}
+
+		filter[fieldName] = field.Interface()
+	}
+
+	return filter
+}
+
+// setCreatedAt stamps the creation time on entities exposing SetCreatedAt
+func setCreatedAt(entity interface{}, t time.Time) {
+	if e, ok := entity.(interface{ SetCreatedAt(time.Time) }); ok {
+		e.SetCreatedAt(t)
 	}
+}
 
-	return mongoSort
+// setUpdatedAt stamps the modification time on entities exposing SetUpdatedAt
+func setUpdatedAt(entity interface{}, t time.Time) {
+	if e, ok := entity.(interface{ SetUpdatedAt(time.Time) }); ok {
+		e.SetUpdatedAt(t)
+	}
 }
@@ -0,0 +1,339 @@
+package mongo_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/interfaces"
+	"github.com/arash-mosavi/go-base-repository/pkg/mongo"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// User is an entity without optimistic locking
+type User struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	Email     string             `bson:"email" json:"email"`
+	Slug      string             `bson:"slug" json:"slug"`
+	Logins    int                `bson:"logins" json:"logins"`
+	Tags      []string           `bson:"tags" json:"tags"`
+	CreatedAt time.Time          `bson:"createdAt" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updatedAt" json:"updated_at"`
+	DeletedAt *time.Time         `bson:"deletedAt,omitempty" json:"deleted_at,omitempty"`
+}
+
+func (u *User) GetID() primitive.ObjectID   { return u.ID }
+func (u *User) SetID(id primitive.ObjectID) { u.ID = id }
+func (u *User) GetSlug() string             { return u.Slug }
+func (u *User) SetSlug(slug string)         { u.Slug = slug }
+func (u *User) GetName() string             { return u.Name }
+func (u *User) SetName(name string)         { u.Name = name }
+func (u *User) GetCreatedAt() time.Time     { return u.CreatedAt }
+func (u *User) GetUpdatedAt() time.Time     { return u.UpdatedAt }
+func (u *User) GetDeletedAt() *time.Time    { return u.DeletedAt }
+func (u *User) SetCreatedAt(t time.Time)    { u.CreatedAt = t }
+func (u *User) SetUpdatedAt(t time.Time)    { u.UpdatedAt = t }
+func (u *User) SetDeletedAt(t *time.Time)   { u.DeletedAt = t }
+func (u *User) IsDeleted() bool             { return u.DeletedAt != nil }
+
+// Account is an entity with optimistic locking
+type Account struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	Slug      string             `bson:"slug" json:"slug"`
+	Version   int64              `bson:"version" json:"version"`
+	CreatedAt time.Time          `bson:"createdAt" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updatedAt" json:"updated_at"`
+	DeletedAt *time.Time         `bson:"deletedAt,omitempty" json:"deleted_at,omitempty"`
+}
+
+func (a *Account) GetID() primitive.ObjectID   { return a.ID }
+func (a *Account) SetID(id primitive.ObjectID) { a.ID = id }
+func (a *Account) GetSlug() string             { return a.Slug }
+func (a *Account) SetSlug(slug string)         { a.Slug = slug }
+func (a *Account) GetName() string             { return a.Name }
+func (a *Account) SetName(name string)         { a.Name = name }
+func (a *Account) GetCreatedAt() time.Time     { return a.CreatedAt }
+func (a *Account) GetUpdatedAt() time.Time     { return a.UpdatedAt }
+func (a *Account) GetDeletedAt() *time.Time    { return a.DeletedAt }
+func (a *Account) SetCreatedAt(t time.Time)    { a.CreatedAt = t }
+func (a *Account) SetUpdatedAt(t time.Time)    { a.UpdatedAt = t }
+func (a *Account) SetDeletedAt(t *time.Time)   { a.DeletedAt = t }
+func (a *Account) IsDeleted() bool             { return a.DeletedAt != nil }
+func (a *Account) GetVersion() int64           { return a.Version }
+func (a *Account) SetVersion(version int64)    { a.Version = version }
+
+// newMock returns a mock deployment answering commands with the responses added to it
+func newMock(t *testing.T) *mtest.T {
+	return mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+}
+
+// command returns the next command sent to the mock deployment, failing when it is not named name
+func command(mt *mtest.T, name string) bson.Raw {
+	mt.Helper()
+
+	event := mt.GetStartedEvent()
+	if event == nil {
+		mt.Fatalf("Expected a %s command, got none", name)
+	}
+	if event.CommandName != name {
+		mt.Fatalf("Expected a %s command, got %s", name, event.CommandName)
+	}
+	return event.Command
+}
+
+// document decodes the document at path in raw, failing when it is missing
+func document(mt *mtest.T, raw bson.Raw, path ...string) bson.M {
+	mt.Helper()
+
+	value, err := raw.LookupErr(path...)
+	if err != nil {
+		mt.Fatalf("Expected %v in %v: %v", path, raw, err)
+	}
+	var doc bson.M
+	if err := bson.Unmarshal(value.Document(), &doc); err != nil {
+		mt.Fatalf("Failed to decode %v: %v", path, err)
+	}
+	return doc
+}
+
+// assertDocument compares got with expected as they are encoded
+func assertDocument(mt *mtest.T, got bson.M, expected interface{}) {
+	mt.Helper()
+
+	raw, err := bson.Marshal(expected)
+	if err != nil {
+		mt.Fatalf("Failed to encode %v: %v", expected, err)
+	}
+	var want bson.M
+	if err := bson.Unmarshal(raw, &want); err != nil {
+		mt.Fatalf("Failed to decode %v: %v", expected, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		mt.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+// found is the reply to a findAndModify command returning doc
+func found(doc interface{}) bson.D {
+	return bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: doc}}
+}
+
+func TestSingleWrites_RequireFilter(t *testing.T) {
+	mt := newMock(t)
+
+	writes := map[string]func(repo interfaces.MongoBaseRepository[*User], filter types.Identifier) error{
+		"Update": func(repo interfaces.MongoBaseRepository[*User], filter types.Identifier) error {
+			_, err := repo.Update(context.Background(), filter, &User{Name: "John"})
+			return err
+		},
+		"Patch": func(repo interfaces.MongoBaseRepository[*User], filter types.Identifier) error {
+			_, err := repo.Patch(context.Background(), filter, types.NewUpdateSet().Set("name", "John"))
+			return err
+		},
+		"Delete": func(repo interfaces.MongoBaseRepository[*User], filter types.Identifier) error {
+			return repo.Delete(context.Background(), filter)
+		},
+		"SoftDelete": func(repo interfaces.MongoBaseRepository[*User], filter types.Identifier) error {
+			_, err := repo.SoftDelete(context.Background(), filter)
+			return err
+		},
+		"HardDelete": func(repo interfaces.MongoBaseRepository[*User], filter types.Identifier) error {
+			_, err := repo.HardDelete(context.Background(), filter)
+			return err
+		},
+		"BulkSoftDelete": func(repo interfaces.MongoBaseRepository[*User], filter types.Identifier) error {
+			return repo.BulkSoftDelete(context.Background(), []types.Identifier{filter})
+		},
+		"BulkHardDelete": func(repo interfaces.MongoBaseRepository[*User], filter types.Identifier) error {
+			return repo.BulkHardDelete(context.Background(), []types.Identifier{filter})
+		},
+	}
+
+	for name, write := range writes {
+		mt.Run(name, func(mt *mtest.T) {
+			repo := mongo.NewBaseRepository[*User](mt.DB)
+
+			for _, filter := range []types.Identifier{nil, identifier.New()} {
+				if err := write(repo, filter); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+					mt.Errorf("Expected ErrInvalidFilter, got %v", err)
+				}
+			}
+			if event := mt.GetStartedEvent(); event != nil {
+				mt.Errorf("Expected no commands, got %s", event.CommandName)
+			}
+		})
+	}
+}
+
+func TestFindAllWithPagination_Where(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("where", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		ns := mt.DB.Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(
+			counted(mt, 42),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch,
+				bson.D{{Key: "name", Value: "John"}},
+				bson.D{{Key: "name", Value: "John"}},
+			),
+		)
+
+		page, total, err := repo.FindAllWithPagination(context.Background(), types.QueryParams[*User]{
+			Filter:  &User{Name: "John"},
+			Where:   identifier.New().GreaterThan("logins", 3).In("slug", []interface{}{"a", "b"}),
+			Limit:   10,
+			Offset:  20,
+			OrderBy: types.SortSpec{{Field: "createdAt", Direction: types.SortDesc}, {Field: "_id", Direction: types.SortAsc}},
+		})
+		if err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		if len(page) != 2 || total != 42 {
+			mt.Errorf("Expected 2 users of 42, got %d of %d", len(page), total)
+		}
+
+		filter := bson.M{
+			"name":      "John",
+			"logins":    bson.M{"$gt": 3},
+			"slug":      bson.M{"$in": bson.A{"a", "b"}},
+			"deletedAt": bson.M{"$exists": false},
+		}
+		assertDocument(mt, document(mt, command(mt, "aggregate"), "pipeline", "0", "$match"), filter)
+
+		find := command(mt, "find")
+		assertDocument(mt, document(mt, find, "filter"), filter)
+		var sort bson.D
+		if err := bson.Unmarshal(find.Lookup("sort").Document(), &sort); err != nil {
+			mt.Fatalf("Failed to decode the sort: %v", err)
+		}
+		if expected := (bson.D{{Key: "createdAt", Value: int32(-1)}, {Key: "_id", Value: int32(1)}}); !reflect.DeepEqual(sort, expected) {
+			mt.Errorf("Expected sort %v, got %v", expected, sort)
+		}
+		if skip, limit := find.Lookup("skip").AsInt64(), find.Lookup("limit").AsInt64(); skip != 20 || limit != 10 {
+			mt.Errorf("Expected skip 20 and limit 10, got %d and %d", skip, limit)
+		}
+	})
+}
+
+func TestFindAll(t *testing.T) {
+	mt := newMock(t)
+
+	tests := map[string]struct {
+		filter   types.Identifier
+		expected bson.M
+	}{
+		"nil":    {nil, bson.M{"deletedAt": bson.M{"$exists": false}}},
+		"filter": {identifier.New().Equal("name", "John").GreaterThan("logins", 1), bson.M{"name": "John", "logins": bson.M{"$gt": 1}, "deletedAt": bson.M{"$exists": false}}},
+	}
+
+	for name, tt := range tests {
+		mt.Run(name, func(mt *mtest.T) {
+			repo := mongo.NewBaseRepository[*User](mt.DB)
+			mt.AddMockResponses(batch(mt, &User{Name: "John"}, &User{Name: "Jane"}))
+
+			found, err := repo.FindAll(context.Background(), tt.filter)
+			if err != nil {
+				mt.Fatalf("Unexpected error: %v", err)
+			}
+			if len(found) != 2 {
+				mt.Errorf("Expected 2 users, got %d", len(found))
+			}
+			assertDocument(mt, document(mt, command(mt, "find"), "filter"), tt.expected)
+		})
+	}
+}
+
+func TestFindAllOptions(t *testing.T) {
+	mt := newMock(t)
+
+	tests := map[string]struct {
+		opts       types.FindOptions
+		sort       bson.D
+		projection bson.D
+	}{
+		"order by": {
+			opts: types.FindOptions{
+				OrderBy: types.SortSpec{
+					{Field: "createdAt", Direction: types.SortDesc, Nulls: types.NullsLast},
+					{Field: "name", Direction: types.SortAsc, Nulls: types.NullsFirst},
+					{Field: "_id", Direction: types.SortAsc},
+				},
+				Projection: []string{"name", "email"},
+			},
+			sort:       bson.D{{Key: "createdAt", Value: int32(-1)}, {Key: "name", Value: int32(1)}, {Key: "_id", Value: int32(1)}},
+			projection: bson.D{{Key: "name", Value: int32(1)}, {Key: "email", Value: int32(1)}},
+		},
+		"sort map": {
+			opts: types.FindOptions{Sort: types.SortMap{"name": types.SortDesc, "createdAt": types.SortAsc}},
+			sort: bson.D{{Key: "createdAt", Value: int32(1)}, {Key: "name", Value: int32(-1)}},
+		},
+	}
+
+	for name, tt := range tests {
+		mt.Run(name, func(mt *mtest.T) {
+			repo := mongo.NewBaseRepository[*User](mt.DB)
+			mt.AddMockResponses(batch(mt))
+
+			tt.opts.Limit = 5
+			if _, err := repo.FindAllOptions(context.Background(), identifier.New().Equal("name", "John"), tt.opts); err != nil {
+				mt.Fatalf("Unexpected error: %v", err)
+			}
+
+			cmd := command(mt, "find")
+			assertDocument(mt, document(mt, cmd, "filter"), bson.M{"name": "John", "deletedAt": bson.M{"$exists": false}})
+			if sort := sortOf(mt, cmd); !reflect.DeepEqual(sort, tt.sort) {
+				mt.Errorf("Expected sort %v, got %v", tt.sort, sort)
+			}
+			if limit := cmd.Lookup("limit").AsInt64(); limit != 5 {
+				mt.Errorf("Expected limit 5, got %d", limit)
+			}
+
+			var projection bson.D
+			if value, err := cmd.LookupErr("projection"); err == nil {
+				if err := bson.Unmarshal(value.Document(), &projection); err != nil {
+					mt.Fatalf("Failed to decode the projection: %v", err)
+				}
+			}
+			if !reflect.DeepEqual(projection, tt.projection) {
+				mt.Errorf("Expected projection %v, got %v", tt.projection, projection)
+			}
+		})
+	}
+}
+
+func TestFindAllOptions_Invalid(t *testing.T) {
+	mt := newMock(t)
+
+	tests := map[string]types.FindOptions{
+		"unknown sort field": {OrderBy: types.SortSpec{{Field: "nickname", Direction: types.SortAsc}}},
+		"unknown direction":  {OrderBy: types.SortSpec{{Field: "name", Direction: "sideways"}}},
+		// MongoDB sorts nulls first when ascending and last when descending
+		"nulls last ascending":   {OrderBy: types.SortSpec{{Field: "name", Direction: types.SortAsc, Nulls: types.NullsLast}}},
+		"nulls first descending": {OrderBy: types.SortSpec{{Field: "name", Direction: types.SortDesc, Nulls: types.NullsFirst}}},
+		"unknown projection":     {Projection: []string{"nickname"}},
+	}
+
+	for name, opts := range tests {
+		mt.Run(name, func(mt *mtest.T) {
+			repo := mongo.NewBaseRepository[*User](mt.DB)
+
+			if _, err := repo.FindAllOptions(context.Background(), nil, opts); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+				mt.Errorf("Expected ErrInvalidFilter, got %v", err)
+			}
+			if event := mt.GetStartedEvent(); event != nil {
+				mt.Errorf("Expected no commands, got %s", event.CommandName)
+			}
+		})
+	}
+}
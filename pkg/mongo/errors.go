@@ -0,0 +1,42 @@
+package mongo
+
+import (
+	"errors"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+)
+
+// Server error codes translated to repository errors
+const (
+	writeConflictCode     = 112
+	noSuchTransactionCode = 251
+)
+
+// translateError maps MongoDB driver errors to the repository errors, keeping err as the cause.
+// Errors that are already translated or have no repository equivalent are returned unchanged.
+func translateError(err error) error {
+	var repoErr *repoErrors.Error
+	if err == nil || errors.As(err, &repoErr) {
+		return err
+	}
+
+	switch {
+	case errors.Is(err, mongoDriver.ErrNoDocuments):
+		return repoErrors.Wrap(repoErrors.ErrNotFound, err)
+	case mongoDriver.IsDuplicateKeyError(err):
+		return repoErrors.Wrap(repoErrors.ErrDuplicateKey, err)
+	case hasErrorCode(err, writeConflictCode):
+		return repoErrors.Wrap(repoErrors.ErrConflict, err)
+	case hasErrorCode(err, noSuchTransactionCode), isTransientError(err):
+		return repoErrors.Wrap(repoErrors.ErrTxAborted, err)
+	}
+
+	return err
+}
+
+// hasErrorCode reports whether any error in err's chain is a server error with the given code
+func hasErrorCode(err error, code int) bool {
+	var serverErr mongoDriver.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(code)
+}
@@ -0,0 +1,53 @@
+package mongo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/mongo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestFindOneById_NotFound(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("not found", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		mt.AddMockResponses(batch(mt))
+
+		_, err := repo.FindOneById(context.Background(), primitive.NewObjectID())
+		if !errors.Is(err, repoErrors.ErrNotFound) {
+			mt.Errorf("Expected ErrNotFound, got %v", err)
+		}
+		if !errors.Is(err, mongoDriver.ErrNoDocuments) {
+			mt.Errorf("Expected the driver error to be kept, got %v", err)
+		}
+	})
+}
+
+func TestInsert_DuplicateKey(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("duplicate key", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000, Message: "E11000 duplicate key error"}))
+
+		_, err := repo.Insert(context.Background(), &User{Name: "John", Email: "john@example.com"})
+		if !errors.Is(err, repoErrors.ErrDuplicateKey) {
+			mt.Fatalf("Expected ErrDuplicateKey, got %v", err)
+		}
+
+		var repoErr *repoErrors.Error
+		if !errors.As(err, &repoErr) {
+			mt.Fatalf("Expected a repository error, got %T", err)
+		}
+		var writeErr mongoDriver.WriteException
+		if !errors.As(errors.Unwrap(repoErr), &writeErr) || !writeErr.HasErrorCode(11000) {
+			mt.Errorf("Expected the driver error as the cause, got %v", errors.Unwrap(repoErr))
+		}
+	})
+}
@@ -0,0 +1,66 @@
+package mongo
+
+import (
+	"fmt"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// toBSON converts any identifier to a MongoDB filter document over the fields of T. A nil
+// filter matches every document; unusable filters and fields T does not have are reported
+// as ErrInvalidFilter.
+func (r *BaseRepository[T]) toBSON(filter types.Identifier) (bson.M, error) {
+	conditions, err := r.conditions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return identifier.Document(conditions), nil
+}
+
+// requiredBSON is toBSON for writes meant for a single document: a filter without conditions is
+// reported as ErrInvalidFilter instead of matching an arbitrary document
+func (r *BaseRepository[T]) requiredBSON(filter types.Identifier) (bson.M, error) {
+	conditions, err := r.conditions(filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireConditions(conditions); err != nil {
+		return nil, err
+	}
+	return identifier.Document(conditions), nil
+}
+
+// manyBSON is toBSON for writes changing every matching document. A filter without conditions
+// is reported as ErrInvalidFilter unless it is identifier.All().
+func (r *BaseRepository[T]) manyBSON(filter types.Identifier) (bson.M, error) {
+	conditions, err := r.conditions(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(conditions) == 0 && !identifier.MatchesAll(filter) {
+		return nil, fmt.Errorf("%w: a filter is required, use identifier.All() to match every document", repoErrors.ErrInvalidFilter)
+	}
+	return identifier.Document(conditions), nil
+}
+
+// conditions returns the conditions of filter with their fields resolved to the fields of T
+func (r *BaseRepository[T]) conditions(filter types.Identifier) ([]identifier.Condition, error) {
+	conditions, err := identifier.Conditions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.fields.Conditions(conditions)
+}
+
+// requireConditions reports ErrInvalidFilter when there are no conditions
+func requireConditions(conditions []identifier.Condition) error {
+	if len(conditions) == 0 {
+		return fmt.Errorf("%w: a filter is required", repoErrors.ErrInvalidFilter)
+	}
+	return nil
+}
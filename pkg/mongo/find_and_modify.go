@@ -0,0 +1,73 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FindOneAndUpdate atomically applies changes, as Patch does, to the first live document matching
+// filter in the order of opts.OrderBy, and returns it as it was before or after the update
+func (r *BaseRepository[T]) FindOneAndUpdate(ctx context.Context, filter types.Identifier, changes map[string]interface{}, opts types.FindAndModifyOptions) (T, error) {
+	var zero T
+
+	query, err := r.toBSON(filter)
+	if err != nil {
+		return zero, fmt.Errorf("failed to find and update: %w", err)
+	}
+	query["deletedAt"] = bson.M{"$exists": false}
+
+	update, err := r.updateDocument(changes)
+	if err != nil {
+		return zero, fmt.Errorf("failed to find and update: %w", err)
+	}
+	sort, err := r.sortDocument(opts.OrderBy)
+	if err != nil {
+		return zero, fmt.Errorf("failed to find and update: %w", err)
+	}
+
+	updateOptions := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	if opts.Return == types.ReturnBefore {
+		updateOptions.SetReturnDocument(options.Before)
+	}
+	if len(sort) > 0 {
+		updateOptions.SetSort(sort)
+	}
+
+	var entity T
+	if err := r.collection.FindOneAndUpdate(r.bind(ctx), query, update, updateOptions).Decode(&entity); err != nil {
+		return zero, fmt.Errorf("failed to find and update: %w", translateError(err))
+	}
+
+	return entity, nil
+}
+
+// FindOneAndDelete atomically removes the first document matching filter in the order of
+// opts.OrderBy and returns it
+func (r *BaseRepository[T]) FindOneAndDelete(ctx context.Context, filter types.Identifier, opts types.FindAndModifyOptions) (T, error) {
+	var zero T
+
+	query, err := r.toBSON(filter)
+	if err != nil {
+		return zero, fmt.Errorf("failed to find and delete: %w", err)
+	}
+	sort, err := r.sortDocument(opts.OrderBy)
+	if err != nil {
+		return zero, fmt.Errorf("failed to find and delete: %w", err)
+	}
+
+	deleteOptions := options.FindOneAndDelete()
+	if len(sort) > 0 {
+		deleteOptions.SetSort(sort)
+	}
+
+	var entity T
+	if err := r.collection.FindOneAndDelete(r.bind(ctx), query, deleteOptions).Decode(&entity); err != nil {
+		return zero, fmt.Errorf("failed to find and delete: %w", translateError(err))
+	}
+
+	return entity, nil
+}
@@ -0,0 +1,91 @@
+package mongo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/mongo"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// oldestFirst picks the earliest created entity
+var oldestFirst = types.FindAndModifyOptions{OrderBy: types.SortSpec{{Field: "createdAt", Direction: types.SortAsc}}}
+
+func TestFindOneAndUpdate(t *testing.T) {
+	mt := newMock(t)
+
+	for _, tt := range []struct {
+		name      string
+		returned  types.ReturnDocument
+		returnNew bool
+	}{
+		{"after", types.ReturnAfter, true},
+		{"before", types.ReturnBefore, false},
+	} {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			repo := mongo.NewBaseRepository[*User](mt.DB)
+			mt.AddMockResponses(found(bson.D{{Key: "name", Value: "John"}}))
+
+			opts := oldestFirst
+			opts.Return = tt.returned
+			user, err := repo.FindOneAndUpdate(context.Background(), identifier.New().Equal("name", "John"), types.NewUpdateSet().Set("slug", "claimed"), opts)
+			if err != nil {
+				mt.Fatalf("Unexpected error: %v", err)
+			}
+			if user.Name != "John" {
+				mt.Errorf("Expected the returned document, got %v", user)
+			}
+
+			cmd := command(mt, "findAndModify")
+			if returnNew, _ := cmd.Lookup("new").BooleanOK(); returnNew != tt.returnNew {
+				mt.Errorf("Expected new to be %v, got %v", tt.returnNew, returnNew)
+			}
+			assertDocument(mt, document(mt, cmd, "query"), bson.M{"name": "John", "deletedAt": bson.M{"$exists": false}})
+			assertDocument(mt, document(mt, cmd, "sort"), bson.M{"createdAt": 1})
+
+			update := document(mt, cmd, "update")
+			set, _ := update["$set"].(bson.M)
+			delete(set, "updatedAt")
+			assertDocument(mt, update, bson.M{"$set": bson.M{"slug": "claimed"}})
+		})
+	}
+
+	mt.Run("not found", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		mt.AddMockResponses(found(nil))
+
+		_, err := repo.FindOneAndUpdate(context.Background(), identifier.New().Equal("name", "John"), types.NewUpdateSet().Set("slug", "claimed"), oldestFirst)
+		if !errors.Is(err, repoErrors.ErrNotFound) {
+			mt.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestFindOneAndDelete(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("deleted", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		mt.AddMockResponses(found(bson.D{{Key: "name", Value: "John"}}))
+
+		user, err := repo.FindOneAndDelete(context.Background(), identifier.New().Equal("name", "John"), oldestFirst)
+		if err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		if user.Name != "John" {
+			mt.Errorf("Expected the deleted document, got %v", user)
+		}
+
+		cmd := command(mt, "findAndModify")
+		if remove, _ := cmd.Lookup("remove").BooleanOK(); !remove {
+			mt.Errorf("Expected a removal, got %v", cmd)
+		}
+		assertDocument(mt, document(mt, cmd, "query"), bson.M{"name": "John"})
+		assertDocument(mt, document(mt, cmd, "sort"), bson.M{"createdAt": 1})
+	})
+}
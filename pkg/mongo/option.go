@@ -0,0 +1,58 @@
+package mongo
+
+import (
+	"github.com/arash-mosavi/go-base-repository/pkg/cursor"
+	"github.com/arash-mosavi/go-base-repository/pkg/retry"
+)
+
+// Option configures a BaseRepository
+type Option func(*settings)
+
+// settings holds the optional BaseRepository configuration
+type settings struct {
+	retry     retry.Policy
+	cursorKey []byte
+	batchSize int
+}
+
+// defaultBatchSize is the number of rows Stream fetches per round trip unless configured otherwise
+const defaultBatchSize = 500
+
+// WithRetryPolicy sets how WithTransaction retries transactions that failed with a transient error
+func WithRetryPolicy(policy retry.Policy) Option {
+	return func(s *settings) {
+		s.retry = policy
+	}
+}
+
+// WithCursorKey sets the key signing FindPage cursors. Instances serving the same clients
+// must share it; without it each process signs with its own random key.
+func WithCursorKey(key []byte) Option {
+	return func(s *settings) {
+		if len(key) > 0 {
+			s.cursorKey = key
+		}
+	}
+}
+
+// WithBatchSize sets how many rows Stream fetches from the database per round trip
+func WithBatchSize(size int) Option {
+	return func(s *settings) {
+		if size > 0 {
+			s.batchSize = size
+		}
+	}
+}
+
+// newSettings applies opts on top of the defaults
+func newSettings(opts []Option) settings {
+	s := settings{
+		retry:     retry.DefaultPolicy(),
+		cursorKey: cursor.DefaultKey(),
+		batchSize: defaultBatchSize,
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
@@ -0,0 +1,145 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/cursor"
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// idField is the MongoDB primary key, used as the keyset pagination tiebreaker
+const idField = "_id"
+
+// FindPage returns one page of the entities matching req.Where, paginated by the sort key
+// plus _id rather than by offset, so pages stay stable under concurrent inserts
+func (r *BaseRepository[T]) FindPage(ctx context.Context, req types.PageRequest) (types.Page[T], error) {
+	var page types.Page[T]
+
+	query, err := r.toBSON(req.Where)
+	if err != nil {
+		return page, fmt.Errorf("failed to find page: %w", err)
+	}
+	query = excludeDeleted(query)
+
+	orderBy, err := r.fields.Sort(req.OrderBy)
+	if err != nil {
+		return page, fmt.Errorf("failed to find page: %w", err)
+	}
+
+	// MongoDB orders missing and null values before all others
+	sort := cursor.Keyset(orderBy, idField, types.NullsFirst)
+	fingerprint, err := cursor.Fingerprint(req.Where, sort)
+	if err != nil {
+		return page, fmt.Errorf("failed to find page: %w", err)
+	}
+
+	current, err := cursor.Parse(r.settings.cursorKey, req.Cursor, fingerprint)
+	if err != nil {
+		return page, fmt.Errorf("failed to find page: %w", err)
+	}
+
+	order := sort
+	if current != nil {
+		if current.Backward {
+			order = cursor.Reverse(sort)
+		}
+
+		after, err := keysetFilter(order, current.Key)
+		if err != nil {
+			return page, fmt.Errorf("failed to find page: %w", err)
+		}
+		query = bson.M{"$and": bson.A{query, after}}
+	}
+
+	mongoSort, err := convertSortMap(order)
+	if err != nil {
+		return page, fmt.Errorf("failed to find page: %w", err)
+	}
+
+	limit := req.PageSize()
+	rows, err := r.find(ctx, query, options.Find().SetSort(mongoSort).SetLimit(int64(limit+1)))
+	if err != nil {
+		return page, err
+	}
+
+	return cursor.BuildPage(r.settings.cursorKey, fingerprint, current, rows, limit, func(entity T) ([]byte, error) {
+		return sortKey(entity, sort)
+	})
+}
+
+// sortKey encodes the values of the sort fields of entity, keeping their BSON types
+func sortKey(entity interface{}, sort types.SortSpec) ([]byte, error) {
+	document, err := bson.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(bson.A, len(sort))
+	for i, field := range sort {
+		value, err := bson.Raw(document).LookupErr(strings.Split(field.Field, ".")...)
+		if err != nil {
+			// a missing field sorts like null
+			continue
+		}
+		values[i] = value
+	}
+
+	return bson.Marshal(bson.M{"v": values})
+}
+
+// keysetFilter matches the documents that come after the position encoded in key when
+// ordered by sort: those ordered after it on the first field, or equal on the first field
+// and after it on the second, and so on
+func keysetFilter(sort types.SortSpec, key []byte) (bson.M, error) {
+	var decoded struct {
+		V bson.A `bson:"v"`
+	}
+	if err := bson.Unmarshal(key, &decoded); err != nil || len(decoded.V) != len(sort) {
+		return nil, fmt.Errorf("%w: cursor does not match the sort order", repoErrors.ErrInvalidFilter)
+	}
+
+	branches := bson.A{}
+	equal := bson.A{}
+	for i, field := range sort {
+		value := decoded.V[i]
+		if after := afterValue(field, value); after != nil {
+			branch := append(append(bson.A{}, equal...), after)
+			branches = append(branches, bson.M{"$and": branch})
+		}
+		equal = append(equal, bson.M{field.Field: value})
+	}
+
+	if len(branches) == 0 {
+		// nothing sorts after the position
+		return bson.M{idField: bson.M{"$in": bson.A{}}}, nil
+	}
+
+	return bson.M{"$or": branches}, nil
+}
+
+// afterValue matches the values of field ordered after value, or returns nil when there are none.
+// Comparison operators never match null, so nulls are handled explicitly: they sort first
+// when ascending and last when descending.
+func afterValue(field types.SortField, value interface{}) bson.M {
+	ascending := field.Direction == types.SortAsc
+
+	if value == nil {
+		if ascending {
+			return bson.M{field.Field: bson.M{"$ne": nil}}
+		}
+		return nil
+	}
+
+	if ascending {
+		return bson.M{field.Field: bson.M{"$gt": value}}
+	}
+	return bson.M{"$or": bson.A{
+		bson.M{field.Field: bson.M{"$lt": value}},
+		bson.M{field.Field: nil},
+	}}
+}
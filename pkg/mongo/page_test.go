@@ -0,0 +1,166 @@
+package mongo_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/mongo"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// byName is the first page of two users with three logins, ordered by name
+var byName = types.PageRequest{
+	Where:   identifier.New().Equal("logins", 3),
+	OrderBy: types.SortSpec{{Field: "name", Direction: types.SortAsc}},
+	Limit:   2,
+}
+
+// batch is the reply to a find command returning users
+func batch(mt *mtest.T, users ...*User) bson.D {
+	docs := make([]bson.D, len(users))
+	for i, user := range users {
+		docs[i] = bson.D{{Key: "_id", Value: user.ID}, {Key: "name", Value: user.Name}}
+	}
+	return mtest.CreateCursorResponse(0, mt.DB.Name()+"."+mt.Coll.Name(), mtest.FirstBatch, docs...)
+}
+
+// names returns the names and IDs of users in order
+func names(users []*User) []string {
+	result := make([]string, len(users))
+	for i, user := range users {
+		result[i] = user.Name + "/" + user.ID.Hex()
+	}
+	return result
+}
+
+// sortOf decodes the sort document of a find command, keeping its order
+func sortOf(mt *mtest.T, cmd bson.Raw) bson.D {
+	mt.Helper()
+
+	var sort bson.D
+	if err := bson.Unmarshal(cmd.Lookup("sort").Document(), &sort); err != nil {
+		mt.Fatalf("Failed to decode the sort: %v", err)
+	}
+	return sort
+}
+
+func TestFindPage(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("forward and back", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		ann := &User{ID: primitive.NewObjectID(), Name: "Ann"}
+		bob := &User{ID: primitive.NewObjectID(), Name: "Bob"}
+		otherBob := &User{ID: primitive.NewObjectID(), Name: "Bob"}
+		live := bson.M{"logins": 3, "deletedAt": bson.M{"$exists": false}}
+
+		mt.AddMockResponses(batch(mt, ann, bob, otherBob))
+		first, err := repo.FindPage(context.Background(), byName)
+		if err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(names(first.Items), names([]*User{ann, bob})) || first.NextCursor == "" || first.PrevCursor != "" {
+			mt.Fatalf("Expected Ann and Bob with only a next cursor, got %v, %+v", names(first.Items), first)
+		}
+
+		cmd := command(mt, "find")
+		assertDocument(mt, document(mt, cmd, "filter"), live)
+		if sort := sortOf(mt, cmd); !reflect.DeepEqual(sort, bson.D{{Key: "name", Value: int32(1)}, {Key: "_id", Value: int32(1)}}) {
+			mt.Errorf("Expected the ID to break ties, got %v", sort)
+		}
+		if limit := cmd.Lookup("limit").AsInt64(); limit != 3 {
+			mt.Errorf("Expected one row more than the page, got %d", limit)
+		}
+
+		next := byName
+		next.Cursor = first.NextCursor
+		mt.AddMockResponses(batch(mt, otherBob))
+		second, err := repo.FindPage(context.Background(), next)
+		if err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(names(second.Items), names([]*User{otherBob})) || second.NextCursor != "" || second.PrevCursor == "" {
+			mt.Fatalf("Expected the other Bob with only a previous cursor, got %v, %+v", names(second.Items), second)
+		}
+
+		// after Bob: a later name, or Bob with a later ID
+		assertDocument(mt, document(mt, command(mt, "find"), "filter"), bson.M{"$and": bson.A{live, bson.M{"$or": bson.A{
+			bson.M{"$and": bson.A{bson.M{"name": bson.M{"$gt": "Bob"}}}},
+			bson.M{"$and": bson.A{bson.M{"name": "Bob"}, bson.M{"_id": bson.M{"$gt": bob.ID}}}},
+		}}}})
+
+		previous := byName
+		previous.Cursor = second.PrevCursor
+		// documents before the cursor are fetched in reverse order
+		mt.AddMockResponses(batch(mt, bob, ann))
+		back, err := repo.FindPage(context.Background(), previous)
+		if err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(names(back.Items), names([]*User{ann, bob})) || back.NextCursor == "" || back.PrevCursor != "" {
+			mt.Errorf("Expected Ann and Bob with only a next cursor, got %v, %+v", names(back.Items), back)
+		}
+
+		// before the other Bob, with nulls last in descending order
+		cmd = command(mt, "find")
+		assertDocument(mt, document(mt, cmd, "filter"), bson.M{"$and": bson.A{live, bson.M{"$or": bson.A{
+			bson.M{"$and": bson.A{bson.M{"$or": bson.A{bson.M{"name": bson.M{"$lt": "Bob"}}, bson.M{"name": nil}}}}},
+			bson.M{"$and": bson.A{bson.M{"name": "Bob"}, bson.M{"$or": bson.A{bson.M{"_id": bson.M{"$lt": otherBob.ID}}, bson.M{"_id": nil}}}}},
+		}}}})
+		if sort := sortOf(mt, cmd); !reflect.DeepEqual(sort, bson.D{{Key: "name", Value: int32(-1)}, {Key: "_id", Value: int32(-1)}}) {
+			mt.Errorf("Expected the reverse order, got %v", sort)
+		}
+	})
+}
+
+func TestFindPage_RejectsForeignCursors(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("rejected", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		mt.AddMockResponses(batch(mt,
+			&User{ID: primitive.NewObjectID(), Name: "Ann"},
+			&User{ID: primitive.NewObjectID(), Name: "Bob"},
+			&User{ID: primitive.NewObjectID(), Name: "Bob"},
+		))
+		first, err := repo.FindPage(context.Background(), byName)
+		if err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		command(mt, "find")
+
+		payload, signature, _ := strings.Cut(first.NextCursor, ".")
+		tampered := []byte(payload)
+		tampered[len(tampered)/2] ^= 1
+
+		otherFilter := byName
+		otherFilter.Where = identifier.New().Equal("logins", 4)
+		otherSort := byName
+		otherSort.OrderBy = types.SortSpec{{Field: "name", Direction: types.SortDesc}}
+		tests := map[string]types.PageRequest{
+			"tampered":     {Where: byName.Where, OrderBy: byName.OrderBy, Cursor: string(tampered) + "." + signature},
+			"other filter": otherFilter,
+			"other sort":   otherSort,
+		}
+
+		for name, req := range tests {
+			if req.Cursor == "" {
+				req.Cursor = first.NextCursor
+			}
+			if _, err := repo.FindPage(context.Background(), req); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+				mt.Errorf("%s: expected ErrInvalidFilter, got %v", name, err)
+			}
+		}
+		if event := mt.GetStartedEvent(); event != nil {
+			mt.Errorf("Expected no commands for rejected cursors, got %s", event.CommandName)
+		}
+	})
+}
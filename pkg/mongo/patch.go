@@ -0,0 +1,179 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// updateOperators maps update operators to their MongoDB counterparts
+var updateOperators = map[types.UpdateOperator]string{
+	types.OpSet:       "$set",
+	types.OpUnset:     "$unset",
+	types.OpIncrement: "$inc",
+	types.OpMultiply:  "$mul",
+	types.OpMin:       "$min",
+	types.OpMax:       "$max",
+	types.OpPush:      "$push",
+	types.OpPull:      "$pull",
+}
+
+// Patch applies changes, a types.UpdateSet or a map of fields to values, to the live document
+// matching filter and returns it as updated. Unlike Update, fields not named in changes are left
+// untouched. For Versioned entities, a version in changes is the version the document is expected
+// to have rather than a new value.
+func (r *BaseRepository[T]) Patch(ctx context.Context, filter types.Identifier, changes map[string]interface{}) (T, error) {
+	var zero T
+
+	query, err := r.requiredBSON(filter)
+	if err != nil {
+		return zero, fmt.Errorf("failed to patch: %w", err)
+	}
+	query["deletedAt"] = bson.M{"$exists": false}
+
+	var version string
+	var checked bool
+	if r.versioned() {
+		if version, err = r.fields.Version(); err != nil {
+			return zero, fmt.Errorf("failed to patch: %w", err)
+		}
+		var expected int64
+		if changes, expected, checked, err = r.fields.ExpectedVersion(changes); err != nil {
+			return zero, fmt.Errorf("failed to patch: %w", err)
+		}
+		if checked {
+			query[version] = matchVersion(expected)
+		}
+	}
+
+	update, err := r.updateDocument(changes)
+	if err != nil {
+		return zero, fmt.Errorf("failed to patch: %w", err)
+	}
+
+	var patched T
+	err = r.collection.FindOneAndUpdate(
+		r.bind(ctx),
+		query,
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&patched)
+	if err != nil {
+		if checked {
+			err = r.versionConflict(ctx, query, version, err)
+		}
+		return zero, fmt.Errorf("failed to patch: %w", translateError(err))
+	}
+
+	return patched, nil
+}
+
+// updateDocument validates changes against the fields of T and renders them as update operators,
+// stamping the modification time on entities that track it and incrementing the version of
+// Versioned entities, which changes must not name
+func (r *BaseRepository[T]) updateDocument(changes map[string]interface{}) (bson.M, error) {
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("%w: no changes", repoErrors.ErrInvalidUpdate)
+	}
+
+	resolved, err := r.fields.Updates(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{}
+	for field, value := range resolved {
+		change := types.AsFieldUpdate(value)
+		operator, ok := updateOperators[change.Operator]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown operator %q on field %q", repoErrors.ErrInvalidUpdate, change.Operator, field)
+		}
+
+		value := change.Value
+		switch change.Operator {
+		case types.OpUnset:
+			value = ""
+		case types.OpPush:
+			value = bson.M{"$each": value}
+		case types.OpPull:
+			value = bson.M{"$in": value}
+		}
+
+		addField(update, operator, field, value)
+	}
+
+	if r.versioned() {
+		version, err := r.fields.Version()
+		if err != nil {
+			return nil, err
+		}
+		if _, changed := resolved[version]; changed {
+			return nil, fmt.Errorf("%w: field %q is incremented on every update, compare it in the filter instead", repoErrors.ErrInvalidUpdate, version)
+		}
+		addField(update, "$inc", version, 1)
+	}
+
+	var entity T
+	if _, ok := interface{}(entity).(interface{ SetUpdatedAt(time.Time) }); ok {
+		if _, changed := resolved["updatedAt"]; !changed {
+			addField(update, "$set", "updatedAt", time.Now())
+		}
+	}
+
+	return update, nil
+}
+
+// addField adds field to the fields update changes with operator
+func addField(update bson.M, operator, field string, value interface{}) {
+	fields, _ := update[operator].(bson.M)
+	if fields == nil {
+		fields = bson.M{}
+		update[operator] = fields
+	}
+	fields[field] = value
+}
+
+// UpdateMany applies changes to every live document matching filter in a single round trip and
+// returns the number of documents matched, including those the changes left as they were. A
+// filter without conditions is rejected; identifier.All() updates every live document.
+func (r *BaseRepository[T]) UpdateMany(ctx context.Context, filter types.Identifier, changes map[string]interface{}) (int64, error) {
+	query, err := r.manyBSON(filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update many: %w", err)
+	}
+	query["deletedAt"] = bson.M{"$exists": false}
+
+	update, err := r.updateDocument(changes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update many: %w", err)
+	}
+
+	result, err := r.collection.UpdateMany(r.bind(ctx), query, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update many: %w", translateError(err))
+	}
+
+	return result.MatchedCount, nil
+}
+
+// DeleteMany permanently removes every document matching filter in a single round trip and
+// returns the number removed. A filter without conditions is rejected; identifier.All() removes
+// every document.
+func (r *BaseRepository[T]) DeleteMany(ctx context.Context, filter types.Identifier) (int64, error) {
+	query, err := r.manyBSON(filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete many: %w", err)
+	}
+
+	result, err := r.collection.DeleteMany(r.bind(ctx), query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete many: %w", translateError(err))
+	}
+
+	return result.DeletedCount, nil
+}
@@ -0,0 +1,158 @@
+package mongo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/mongo"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestPatch(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("operators", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		mt.AddMockResponses(found(bson.D{{Key: "name", Value: "John"}, {Key: "logins", Value: 2}}))
+
+		changes := types.NewUpdateSet().Increment("logins", 1).Push("tags", "a", "b").Unset("slug")
+		user, err := repo.Patch(context.Background(), identifier.New().Equal("name", "John"), changes)
+		if err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		if user.Logins != 2 {
+			mt.Errorf("Expected the patched document, got %v", user)
+		}
+
+		cmd := command(mt, "findAndModify")
+		if returnNew, ok := cmd.Lookup("new").BooleanOK(); !ok || !returnNew {
+			mt.Error("Expected the document to be returned as updated")
+		}
+		assertDocument(mt, document(mt, cmd, "query"), bson.M{"name": "John", "deletedAt": bson.M{"$exists": false}})
+
+		update := document(mt, cmd, "update")
+		set, _ := update["$set"].(bson.M)
+		if _, ok := set["updatedAt"]; !ok {
+			mt.Errorf("Expected the modification time to be set, got %v", update)
+		}
+		delete(update, "$set")
+		assertDocument(mt, update, bson.M{
+			"$inc":   bson.M{"logins": 1},
+			"$push":  bson.M{"tags": bson.M{"$each": bson.A{"a", "b"}}},
+			"$unset": bson.M{"slug": ""},
+		})
+	})
+
+	mt.Run("rejected", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+
+		for _, changes := range []types.UpdateSet{
+			types.NewUpdateSet(),
+			types.NewUpdateSet().Set("missing", 1),
+			types.NewUpdateSet().Increment("name", 1),
+		} {
+			if _, err := repo.Patch(context.Background(), identifier.New().Equal("name", "John"), changes); !errors.Is(err, repoErrors.ErrInvalidUpdate) {
+				mt.Errorf("Expected ErrInvalidUpdate for %v, got %v", changes, err)
+			}
+		}
+		if event := mt.GetStartedEvent(); event != nil {
+			mt.Errorf("Expected no commands, got %s", event.CommandName)
+		}
+	})
+}
+
+func TestUpdateMany(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("matched", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 3}, {Key: "nModified", Value: 2}})
+
+		count, err := repo.UpdateMany(context.Background(), identifier.New().GreaterThan("logins", 10), types.NewUpdateSet().Set("name", "Frequent"))
+		if err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		if count != 3 {
+			mt.Errorf("Expected 3 matched documents, got %d", count)
+		}
+
+		cmd := command(mt, "update")
+		statement := document(mt, cmd, "updates", "0")
+		if multi, _ := statement["multi"].(bool); !multi {
+			mt.Errorf("Expected a multi-document update, got %v", statement)
+		}
+		assertDocument(mt, statement["q"].(bson.M), bson.M{"logins": bson.M{"$gt": 10}, "deletedAt": bson.M{"$exists": false}})
+
+		update := statement["u"].(bson.M)
+		set, _ := update["$set"].(bson.M)
+		if _, ok := set["updatedAt"]; !ok {
+			mt.Errorf("Expected the modification time to be set, got %v", update)
+		}
+		delete(set, "updatedAt")
+		assertDocument(mt, update, bson.M{"$set": bson.M{"name": "Frequent"}})
+	})
+}
+
+func TestDeleteMany(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("deleted", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 4}})
+
+		count, err := repo.DeleteMany(context.Background(), identifier.New().GreaterThan("logins", 10))
+		if err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		if count != 4 {
+			mt.Errorf("Expected 4 deleted documents, got %d", count)
+		}
+
+		statement := document(mt, command(mt, "delete"), "deletes", "0")
+		if limit, _ := statement["limit"].(int32); limit != 0 {
+			mt.Errorf("Expected every match to be deleted, got %v", statement)
+		}
+		assertDocument(mt, statement["q"].(bson.M), bson.M{"logins": bson.M{"$gt": 10}})
+	})
+}
+
+func TestWriteMany_RequireFilter(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("rejected", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+
+		for _, filter := range []types.Identifier{nil, identifier.New()} {
+			if _, err := repo.UpdateMany(context.Background(), filter, types.NewUpdateSet().Set("name", "Everyone")); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+				mt.Errorf("Expected ErrInvalidFilter from UpdateMany, got %v", err)
+			}
+			if _, err := repo.DeleteMany(context.Background(), filter); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+				mt.Errorf("Expected ErrInvalidFilter from DeleteMany, got %v", err)
+			}
+		}
+		if event := mt.GetStartedEvent(); event != nil {
+			mt.Errorf("Expected no commands, got %s", event.CommandName)
+		}
+	})
+
+	mt.Run("all", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 2}})
+
+		count, err := repo.DeleteMany(context.Background(), identifier.All())
+		if err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		if count != 2 {
+			mt.Errorf("Expected 2 deleted documents, got %d", count)
+		}
+
+		statement := document(mt, command(mt, "delete"), "deletes", "0")
+		assertDocument(mt, statement["q"].(bson.M), bson.M{})
+	})
+}
@@ -0,0 +1,50 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Stream iterates over the entities matching the identifier through a server-side cursor,
+// fetching them in batches of the configured batch size instead of loading them all at once.
+// The cursor is closed when the iteration ends, the consumer stops early or ctx is cancelled;
+// a failure is yielded once as the last element.
+func (r *BaseRepository[T]) Stream(ctx context.Context, filter types.Identifier) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		query, err := r.toBSON(filter)
+		if err != nil {
+			yield(zero, fmt.Errorf("failed to stream: %w", err))
+			return
+		}
+
+		findOptions := options.Find().SetBatchSize(int32(r.settings.batchSize))
+		cursor, err := r.collection.Find(r.bind(ctx), excludeDeleted(query), findOptions)
+		if err != nil {
+			yield(zero, fmt.Errorf("failed to stream: %w", translateError(err)))
+			return
+		}
+		// the cursor must be killed on the server even when ctx is already cancelled
+		defer cursor.Close(context.WithoutCancel(ctx))
+
+		for cursor.Next(ctx) {
+			var entity T
+			if err := cursor.Decode(&entity); err != nil {
+				yield(zero, fmt.Errorf("failed to decode result: %w", translateError(err)))
+				return
+			}
+			if !yield(entity, nil) {
+				return
+			}
+		}
+
+		if err := cursor.Err(); err != nil {
+			yield(zero, fmt.Errorf("failed to stream: %w", translateError(err)))
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package mongo_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/mongo"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// streamed is the reply to a find or getMore command returning users named names from cursor id
+func streamed(mt *mtest.T, id int64, kind mtest.BatchIdentifier, names ...string) bson.D {
+	docs := make([]bson.D, len(names))
+	for i, name := range names {
+		docs[i] = bson.D{{Key: "name", Value: name}}
+	}
+	return mtest.CreateCursorResponse(id, mt.DB.Name()+"."+mt.Coll.Name(), kind, docs...)
+}
+
+// killed returns the cursor IDs a killCursors command closes
+func killed(mt *mtest.T) []int64 {
+	mt.Helper()
+
+	var cmd struct {
+		Cursors []int64 `bson:"cursors"`
+	}
+	if err := bson.Unmarshal(command(mt, "killCursors"), &cmd); err != nil {
+		mt.Fatalf("Failed to decode killCursors: %v", err)
+	}
+	return cmd.Cursors
+}
+
+func TestStream_Batches(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("batches", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB, mongo.WithBatchSize(2))
+		mt.AddMockResponses(
+			streamed(mt, 42, mtest.FirstBatch, "Ann", "Bob"),
+			streamed(mt, 0, mtest.NextBatch, "Cid"),
+		)
+
+		var got []string
+		for user, err := range repo.Stream(context.Background(), identifier.New().Equal("name", "John")) {
+			if err != nil {
+				mt.Fatalf("Unexpected error: %v", err)
+			}
+			got = append(got, user.Name)
+		}
+
+		if expected := []string{"Ann", "Bob", "Cid"}; !reflect.DeepEqual(got, expected) {
+			mt.Errorf("Expected %v, got %v", expected, got)
+		}
+
+		find := command(mt, "find")
+		if size := find.Lookup("batchSize").AsInt64(); size != 2 {
+			mt.Errorf("Expected find batchSize 2, got %d", size)
+		}
+		assertDocument(mt, document(mt, find, "filter"), bson.M{
+			"name":      "John",
+			"deletedAt": bson.M{"$exists": false},
+		})
+
+		getMore := command(mt, "getMore")
+		if id := getMore.Lookup("getMore").Int64(); id != 42 {
+			mt.Errorf("Expected getMore on cursor 42, got %d", id)
+		}
+		if size := getMore.Lookup("batchSize").AsInt64(); size != 2 {
+			mt.Errorf("Expected getMore batchSize 2, got %d", size)
+		}
+		if event := mt.GetStartedEvent(); event != nil {
+			mt.Errorf("Expected no command after the exhausted cursor, got %s", event.CommandName)
+		}
+	})
+}
+
+func TestStream_StopEarly(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("stop early", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB, mongo.WithBatchSize(2))
+		mt.AddMockResponses(
+			streamed(mt, 42, mtest.FirstBatch, "Ann", "Bob"),
+			mtest.CreateSuccessResponse(),
+		)
+
+		var got []string
+		for user, err := range repo.Stream(context.Background(), identifier.New()) {
+			if err != nil {
+				mt.Fatalf("Unexpected error: %v", err)
+			}
+			got = append(got, user.Name)
+			break
+		}
+
+		if expected := []string{"Ann"}; !reflect.DeepEqual(got, expected) {
+			mt.Errorf("Expected %v, got %v", expected, got)
+		}
+
+		command(mt, "find")
+		if cursors := killed(mt); !reflect.DeepEqual(cursors, []int64{42}) {
+			mt.Errorf("Expected cursor 42 to be killed, got %v", cursors)
+		}
+		if event := mt.GetStartedEvent(); event != nil {
+			mt.Errorf("Expected no command after killCursors, got %s", event.CommandName)
+		}
+	})
+}
+
+func TestStream_Cancel(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("cancel", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB, mongo.WithBatchSize(1))
+		mt.AddMockResponses(
+			streamed(mt, 42, mtest.FirstBatch, "Ann"),
+			mtest.CreateSuccessResponse(),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var got []string
+		var errs []error
+		for user, err := range repo.Stream(ctx, identifier.New()) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			got = append(got, user.Name)
+			cancel()
+		}
+
+		if expected := []string{"Ann"}; !reflect.DeepEqual(got, expected) {
+			mt.Errorf("Expected %v, got %v", expected, got)
+		}
+		if len(errs) != 1 || !errors.Is(errs[0], context.Canceled) {
+			mt.Errorf("Expected context.Canceled once, got %v", errs)
+		}
+
+		command(mt, "find")
+		command(mt, "getMore")
+		if cursors := killed(mt); !reflect.DeepEqual(cursors, []int64{42}) {
+			mt.Errorf("Expected cursor 42 to be killed after cancellation, got %v", cursors)
+		}
+	})
+}
@@ -0,0 +1,201 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/interfaces"
+	"github.com/arash-mosavi/go-base-repository/pkg/retry"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+)
+
+// Errors of the deprecated BeginTransaction, CommitTransaction and RollbackTransaction
+var (
+	errTransactionBegun = errors.New("a transaction is already in progress on this repository")
+	errNoTransaction    = errors.New("no transaction in progress on this repository")
+)
+
+// Error labels the server attaches to transaction failures that are safe to retry
+const (
+	transientTransactionLabel = "TransientTransactionError"
+	unknownCommitResultLabel  = "UnknownTransactionCommitResult"
+)
+
+// WithTransaction runs fn inside a MongoDB transaction. The session is bound to the
+// context handed to fn, so every repository method called with that context takes part
+// in the transaction. The transaction is committed when fn returns nil and aborted when
+// it returns an error or panics. If ctx already carries a session, or one was begun with
+// BeginTransaction, fn joins it.
+//
+// A transaction that fails with a TransientTransactionError is run again, and a commit
+// that fails with an UnknownTransactionCommitResult is retried, as configured by
+// WithRetryPolicy. fn may therefore be called more than once and must be safe to repeat.
+//
+// opts sets the read concern, write concern and read preference of the transaction and
+// its maximum duration; isolation level and read-only mode do not apply to MongoDB.
+func (r *BaseRepository[T]) WithTransaction(ctx context.Context, fn func(ctx context.Context, repo interfaces.MongoBaseRepository[T]) error, opts ...*types.TxOptions) error {
+	txOptions := types.TxOptionsFrom(opts)
+	if txOptions.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, txOptions.MaxDuration)
+		defer cancel()
+	}
+
+	if ctx = r.bind(ctx); mongoDriver.SessionFromContext(ctx) != nil {
+		return fn(ctx, r)
+	}
+
+	err := retry.Do(ctx, r.settings.retry, isTransientError, func() error {
+		return r.runTransaction(ctx, txOptions, fn)
+	})
+	return translateError(err)
+}
+
+// runTransaction makes a single attempt at running fn in a new session and transaction
+func (r *BaseRepository[T]) runTransaction(ctx context.Context, txOptions types.TxOptions, fn func(ctx context.Context, repo interfaces.MongoBaseRepository[T]) error) error {
+	session, err := r.client.StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(context.WithoutCancel(ctx))
+
+	if err := session.StartTransaction(txOptions.MongoOptions()); err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	sessionCtx := mongoDriver.NewSessionContext(ctx, session)
+	defer func() {
+		if p := recover(); p != nil {
+			_ = session.AbortTransaction(context.WithoutCancel(ctx))
+			panic(p)
+		}
+	}()
+
+	if err := fn(sessionCtx, r); err != nil {
+		if abortErr := session.AbortTransaction(context.WithoutCancel(ctx)); abortErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to abort transaction: %w", abortErr))
+		}
+		return err
+	}
+
+	commit := func() error {
+		return session.CommitTransaction(sessionCtx)
+	}
+	if err := retry.Do(ctx, r.settings.retry, isUnknownCommitResult, commit); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// isTransientError reports whether the whole transaction can be run again after err
+func isTransientError(err error) bool {
+	return hasErrorLabel(err, transientTransactionLabel)
+}
+
+// isUnknownCommitResult reports whether the commit can be retried after err
+func isUnknownCommitResult(err error) bool {
+	return hasErrorLabel(err, unknownCommitResultLabel)
+}
+
+// hasErrorLabel reports whether any error in err's chain carries the server error label
+func hasErrorLabel(err error, label string) bool {
+	var labeled mongoDriver.LabeledError
+	return errors.As(err, &labeled) && labeled.HasErrorLabel(label)
+}
+
+// manualSession is the session whose transaction was begun by the deprecated BeginTransaction
+type manualSession struct {
+	mu      sync.Mutex
+	session mongoDriver.Session
+}
+
+// bind returns ctx carrying the session of the transaction begun by BeginTransaction, unless ctx
+// already carries a session or no such transaction is in progress
+func (r *BaseRepository[T]) bind(ctx context.Context) context.Context {
+	if mongoDriver.SessionFromContext(ctx) != nil {
+		return ctx
+	}
+
+	r.manual.mu.Lock()
+	session := r.manual.session
+	r.manual.mu.Unlock()
+
+	if session == nil {
+		return ctx
+	}
+	return mongoDriver.NewSessionContext(ctx, session)
+}
+
+// BeginTransaction starts a session and transaction that every later call on this repository
+// takes part in, from any goroutine, until CommitTransaction or RollbackTransaction ends it.
+//
+// Deprecated: the transaction belongs to the repository rather than to a context, so it cannot
+// be shared with other repositories and leaks into concurrent callers. Use WithTransaction.
+func (r *BaseRepository[T]) BeginTransaction(ctx context.Context) error {
+	r.manual.mu.Lock()
+	defer r.manual.mu.Unlock()
+
+	if r.manual.session != nil {
+		return errTransactionBegun
+	}
+
+	session, err := r.client.StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	if err := session.StartTransaction(); err != nil {
+		session.EndSession(ctx)
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	r.manual.session = session
+	return nil
+}
+
+// CommitTransaction commits the transaction begun by BeginTransaction and ends its session.
+//
+// Deprecated: use WithTransaction.
+func (r *BaseRepository[T]) CommitTransaction(ctx context.Context) error {
+	session, err := r.manual.end()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.WithoutCancel(ctx))
+
+	if err := session.CommitTransaction(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", translateError(err))
+	}
+	return nil
+}
+
+// RollbackTransaction aborts the transaction begun by BeginTransaction and ends its session.
+//
+// Deprecated: use WithTransaction.
+func (r *BaseRepository[T]) RollbackTransaction(ctx context.Context) error {
+	session, err := r.manual.end()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(context.WithoutCancel(ctx))
+
+	if err := session.AbortTransaction(context.WithoutCancel(ctx)); err != nil {
+		return fmt.Errorf("failed to abort transaction: %w", translateError(err))
+	}
+	return nil
+}
+
+// end detaches the session in progress so its transaction can be committed or aborted
+func (m *manualSession) end() (mongoDriver.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session := m.session
+	if session == nil {
+		return nil, errNoTransaction
+	}
+	m.session = nil
+	return session, nil
+}
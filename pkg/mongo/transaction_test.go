@@ -0,0 +1,42 @@
+package mongo_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/mongo"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestManualTransaction(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("insert", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		ctx := context.Background()
+
+		if err := repo.BeginTransaction(ctx); err != nil {
+			mt.Fatalf("Failed to begin transaction: %v", err)
+		}
+		if err := repo.BeginTransaction(ctx); err == nil {
+			mt.Error("Expected an error beginning a second transaction")
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+		if _, err := repo.Insert(ctx, &User{Name: "John"}); err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		if err := repo.CommitTransaction(ctx); err != nil {
+			mt.Fatalf("Failed to commit transaction: %v", err)
+		}
+		if err := repo.RollbackTransaction(ctx); err == nil {
+			mt.Error("Expected an error without a transaction in progress")
+		}
+
+		insert := command(mt, "insert")
+		if started, ok := insert.Lookup("startTransaction").BooleanOK(); !ok || !started {
+			mt.Errorf("Expected the insert to start the transaction, got %v", insert)
+		}
+		command(mt, "commitTransaction")
+	})
+}
@@ -0,0 +1,135 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// Upsert overwrites the live document matching filter with entity, or inserts entity when none
+// matches, in a single upserting findOneAndUpdate. A soft-deleted document matching filter is
+// left alone and ErrConflict is returned. With UpdateFields set, an existing document only has
+// those fields overwritten; otherwise every field but the identifier and the creation time is.
+// The rest of entity, including its creation time, is written on insert alone. The stored
+// document is returned.
+func (r *BaseRepository[T]) Upsert(ctx context.Context, filter types.Identifier, entity T, opts types.UpsertOptions) (T, error) {
+	query, err := r.toBSON(filter)
+	if err != nil {
+		return entity, fmt.Errorf("failed to upsert: %w", err)
+	}
+	query["deletedAt"] = bson.M{"$exists": false}
+
+	updates, err := r.fields.Fields(opts.UpdateFields)
+	if err != nil {
+		return entity, fmt.Errorf("failed to upsert: %w", err)
+	}
+	if len(updates) == 0 {
+		for _, name := range r.fields.Names() {
+			if name != "_id" && name != "createdAt" {
+				updates = append(updates, name)
+			}
+		}
+	}
+
+	now := time.Now()
+	if entity.GetCreatedAt().IsZero() {
+		setCreatedAt(entity, now)
+	}
+	setUpdatedAt(entity, now)
+
+	update, err := upsertDocument(entity, updates)
+	if err != nil {
+		return entity, fmt.Errorf("failed to upsert: %w", err)
+	}
+
+	var upserted T
+	err = r.collection.FindOneAndUpdate(
+		r.bind(ctx),
+		query,
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&upserted)
+	if err != nil {
+		err = r.trashedConflict(ctx, query, err)
+		return entity, fmt.Errorf("failed to upsert: %w", translateError(err))
+	}
+
+	return upserted, nil
+}
+
+// trashedConflict turns the duplicate key error of an upsert whose filter matches a soft-deleted
+// document into ErrConflict, as that document is neither restored nor overwritten
+func (r *BaseRepository[T]) trashedConflict(ctx context.Context, query bson.M, err error) error {
+	if !mongoDriver.IsDuplicateKeyError(err) {
+		return err
+	}
+
+	trashed := make(bson.M, len(query))
+	for key, value := range query {
+		trashed[key] = value
+	}
+	trashed["deletedAt"] = bson.M{"$exists": true}
+
+	count, countErr := r.collection.CountDocuments(r.bind(ctx), trashed, options.Count().SetLimit(1))
+	if countErr != nil || count == 0 {
+		return err
+	}
+	return fmt.Errorf("a soft-deleted document matches the filter: %w", repoErrors.ErrConflict)
+}
+
+// upsertDocument builds the update that overwrites the paths of entity listed in updates, along
+// with its modification time, and writes the rest of entity only when it is inserted
+func upsertDocument(entity interface{}, updates []string) (bson.M, error) {
+	raw, err := bson.Marshal(entity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode entity: %w", err)
+	}
+	document := bson.Raw(raw)
+
+	set := bson.M{}
+	unset := bson.M{}
+	roots := map[string]bool{"updatedAt": true}
+	for _, path := range append(updates, "updatedAt") {
+		roots[strings.SplitN(path, ".", 2)[0]] = true
+
+		value, err := document.LookupErr(strings.Split(path, ".")...)
+		switch {
+		case errors.Is(err, bsoncore.ErrElementNotFound):
+			// omitted by the encoder, so clear it like a replacement would
+			unset[path] = ""
+		case err != nil:
+			return nil, fmt.Errorf("failed to read field %q: %w", path, err)
+		default:
+			set[path] = value
+		}
+	}
+
+	elements, err := document.Elements()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode entity: %w", err)
+	}
+	insert := bson.M{}
+	for _, element := range elements {
+		if !roots[element.Key()] {
+			insert[element.Key()] = element.Value()
+		}
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	if len(insert) > 0 {
+		update["$setOnInsert"] = insert
+	}
+	return update, nil
+}
@@ -0,0 +1,102 @@
+package mongo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/mongo"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestUpsert(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("replace", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		mt.AddMockResponses(found(bson.D{{Key: "name", Value: "John"}}))
+
+		created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		user := &User{Name: "John", Email: "john@example.com", CreatedAt: created}
+		upserted, err := repo.Upsert(context.Background(), identifier.New().Equal("email", "john@example.com"), user, types.UpsertOptions{})
+		if err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		if upserted.Name != "John" {
+			mt.Errorf("Expected the stored document, got %v", upserted)
+		}
+
+		cmd := command(mt, "findAndModify")
+		if upsert, ok := cmd.Lookup("upsert").BooleanOK(); !ok || !upsert {
+			mt.Error("Expected an upsert")
+		}
+		assertDocument(mt, document(mt, cmd, "query"), bson.M{"email": "john@example.com", "deletedAt": bson.M{"$exists": false}})
+
+		update := document(mt, cmd, "update")
+		set, _ := update["$set"].(bson.M)
+		if _, ok := set["updatedAt"]; !ok {
+			mt.Errorf("Expected the modification time to be set, got %v", update)
+		}
+		delete(set, "updatedAt")
+		assertDocument(mt, set, bson.M{"name": "John", "email": "john@example.com", "slug": "", "logins": 0, "tags": nil})
+		assertDocument(mt, document(mt, cmd, "update", "$setOnInsert"), bson.M{"createdAt": created})
+		assertDocument(mt, document(mt, cmd, "update", "$unset"), bson.M{"deletedAt": ""})
+	})
+
+	mt.Run("update fields", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		mt.AddMockResponses(found(bson.D{{Key: "name", Value: "John"}}))
+
+		user := &User{Name: "John", Email: "john@example.com"}
+		_, err := repo.Upsert(context.Background(), identifier.New().Equal("email", "john@example.com"), user, types.UpsertOptions{UpdateFields: []string{"name"}})
+		if err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+
+		update := document(mt, command(mt, "findAndModify"), "update")
+		set, _ := update["$set"].(bson.M)
+		delete(set, "updatedAt")
+		assertDocument(mt, set, bson.M{"name": "John"})
+
+		insert, _ := update["$setOnInsert"].(bson.M)
+		if _, ok := insert["createdAt"]; !ok {
+			mt.Errorf("Expected the creation time to be set on insert, got %v", update)
+		}
+		delete(insert, "createdAt")
+		assertDocument(mt, insert, bson.M{"email": "john@example.com", "slug": "", "logins": 0, "tags": nil})
+	})
+}
+
+func TestUpsert_SoftDeleted(t *testing.T) {
+	mt := newMock(t)
+	duplicate := bson.D{{Key: "ok", Value: 0}, {Key: "code", Value: 11000}, {Key: "errmsg", Value: "E11000 duplicate key error"}}
+
+	mt.Run("soft-deleted", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		mt.AddMockResponses(duplicate, counted(mt, 1))
+
+		_, err := repo.Upsert(context.Background(), identifier.New().Equal("email", "john@example.com"), &User{Name: "John", Email: "john@example.com"}, types.UpsertOptions{})
+		if !errors.Is(err, repoErrors.ErrConflict) {
+			mt.Errorf("Expected ErrConflict, got %v", err)
+		}
+
+		command(mt, "findAndModify")
+		query := document(mt, command(mt, "aggregate"), "pipeline", "0", "$match")
+		assertDocument(mt, query, bson.M{"email": "john@example.com", "deletedAt": bson.M{"$exists": true}})
+	})
+
+	mt.Run("duplicate key", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*User](mt.DB)
+		mt.AddMockResponses(duplicate, counted(mt, 0))
+
+		_, err := repo.Upsert(context.Background(), identifier.New().Equal("email", "john@example.com"), &User{Name: "John", Email: "john@example.com"}, types.UpsertOptions{})
+		if !errors.Is(err, repoErrors.ErrDuplicateKey) {
+			mt.Errorf("Expected ErrDuplicateKey, got %v", err)
+		}
+	})
+}
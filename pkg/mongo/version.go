@@ -0,0 +1,50 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// versioned reports whether T opts in to optimistic locking
+func (r *BaseRepository[T]) versioned() bool {
+	var entity T
+	_, ok := interface{}(entity).(types.Versioned)
+	return ok
+}
+
+// matchVersion returns the condition matching documents stored with version. Documents written
+// before the entity was versioned have no version, which counts as zero.
+func matchVersion(version int64) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{0, nil}}
+	}
+	return version
+}
+
+// versionConflict turns err, the failure of a write to the document matching query, into
+// ErrConflict when the document exists with another version than query expects
+func (r *BaseRepository[T]) versionConflict(ctx context.Context, query bson.M, field string, err error) error {
+	if !errors.Is(err, mongoDriver.ErrNoDocuments) {
+		return err
+	}
+
+	unversioned := make(bson.M, len(query))
+	for key, value := range query {
+		if key != field {
+			unversioned[key] = value
+		}
+	}
+
+	count, countErr := r.collection.CountDocuments(r.bind(ctx), unversioned, options.Count().SetLimit(1))
+	if countErr != nil || count == 0 {
+		return err
+	}
+	return fmt.Errorf("the stored version differs: %w", repoErrors.ErrConflict)
+}
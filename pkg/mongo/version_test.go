@@ -0,0 +1,136 @@
+package mongo_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/mongo"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// counted is the reply to the aggregation of a CountDocuments matching n documents
+func counted(mt *mtest.T, n int) bson.D {
+	return mtest.CreateCursorResponse(0, mt.DB.Name()+"."+mt.Coll.Name(), mtest.FirstBatch, bson.D{{Key: "n", Value: n}})
+}
+
+func TestSoftDelete_ExpectedVersion(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("matched", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*Account](mt.DB)
+		mt.AddMockResponses(found(bson.D{{Key: "name", Value: "John"}, {Key: "version", Value: int64(4)}}))
+
+		deleted, err := repo.SoftDelete(context.Background(), identifier.New().Equal("name", "John").Equal("version", 3))
+		if err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		if deleted.Version != 4 {
+			mt.Errorf("Expected version 4, got %d", deleted.Version)
+		}
+
+		cmd := command(mt, "findAndModify")
+		assertDocument(mt, document(mt, cmd, "query"), bson.M{"name": "John", "version": int64(3), "deletedAt": bson.M{"$exists": false}})
+		assertDocument(mt, document(mt, cmd, "update", "$inc"), bson.M{"version": 1})
+	})
+
+	mt.Run("unversioned", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*Account](mt.DB)
+		mt.AddMockResponses(found(bson.D{{Key: "name", Value: "John"}, {Key: "version", Value: int64(1)}}))
+
+		if _, err := repo.SoftDelete(context.Background(), identifier.New().Equal("name", "John").Equal("version", 0)); err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+
+		query := document(mt, command(mt, "findAndModify"), "query")
+		assertDocument(mt, query, bson.M{"name": "John", "version": bson.M{"$in": bson.A{0, nil}}, "deletedAt": bson.M{"$exists": false}})
+	})
+
+	mt.Run("conflict", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*Account](mt.DB)
+		mt.AddMockResponses(found(nil), counted(mt, 1))
+
+		_, err := repo.SoftDelete(context.Background(), identifier.New().Equal("name", "John").Equal("version", 3))
+		if !errors.Is(err, repoErrors.ErrConflict) {
+			mt.Errorf("Expected ErrConflict, got %v", err)
+		}
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*Account](mt.DB)
+		mt.AddMockResponses(found(nil), counted(mt, 0))
+
+		_, err := repo.SoftDelete(context.Background(), identifier.New().Equal("name", "John").Equal("version", 3))
+		if !errors.Is(err, repoErrors.ErrNotFound) {
+			mt.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestUpdate_Conflict(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("conflict", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*Account](mt.DB)
+		mt.AddMockResponses(found(nil), counted(mt, 1))
+
+		entity := &Account{Name: "Jane", Version: 3}
+		_, err := repo.Update(context.Background(), identifier.New().Equal("name", "John"), entity)
+		if !errors.Is(err, repoErrors.ErrConflict) {
+			mt.Errorf("Expected ErrConflict, got %v", err)
+		}
+		if entity.Version != 3 {
+			mt.Errorf("Expected the version to be restored, got %d", entity.Version)
+		}
+
+		query := document(mt, command(mt, "findAndModify"), "query")
+		assertDocument(mt, query, bson.M{"name": "John", "version": int64(3), "deletedAt": bson.M{"$exists": false}})
+	})
+}
+
+func TestPatch_ExpectedVersion(t *testing.T) {
+	mt := newMock(t)
+
+	mt.Run("conflict", func(mt *mtest.T) {
+		repo := mongo.NewBaseRepository[*Account](mt.DB)
+		mt.AddMockResponses(found(nil), counted(mt, 1))
+
+		_, err := repo.Patch(context.Background(), identifier.New().Equal("name", "John"), types.NewUpdateSet().Set("name", "Jane").Set("version", 3))
+		if !errors.Is(err, repoErrors.ErrConflict) {
+			mt.Errorf("Expected ErrConflict, got %v", err)
+		}
+
+		cmd := command(mt, "findAndModify")
+		assertDocument(mt, document(mt, cmd, "query"), bson.M{"name": "John", "version": int64(3), "deletedAt": bson.M{"$exists": false}})
+		assertDocument(mt, document(mt, cmd, "update", "$inc"), bson.M{"version": 1})
+	})
+}
+
+func TestModify_RejectsVersion(t *testing.T) {
+	mt := newMock(t)
+	changes := map[string]types.UpdateSet{
+		"set":       types.NewUpdateSet().Set("name", "Jane").Set("version", 1),
+		"increment": types.NewUpdateSet().Increment("version", 10),
+	}
+
+	for name, change := range changes {
+		mt.Run(name, func(mt *mtest.T) {
+			repo := mongo.NewBaseRepository[*Account](mt.DB)
+			filter := identifier.New().Equal("name", "John")
+
+			if _, err := repo.UpdateMany(context.Background(), filter, change); !errors.Is(err, repoErrors.ErrInvalidUpdate) {
+				mt.Errorf("Expected UpdateMany to fail with ErrInvalidUpdate, got %v", err)
+			}
+			if _, err := repo.FindOneAndUpdate(context.Background(), filter, change, types.FindAndModifyOptions{}); !errors.Is(err, repoErrors.ErrInvalidUpdate) {
+				mt.Errorf("Expected FindOneAndUpdate to fail with ErrInvalidUpdate, got %v", err)
+			}
+			if event := mt.GetStartedEvent(); event != nil {
+				mt.Errorf("Expected no commands, got %s", event.CommandName)
+			}
+		})
+	}
+}
@@ -2,214 +2,497 @@ package postgres
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
 
-	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
 	"github.com/arash-mosavi/go-base-repository/pkg/interfaces"
 	"github.com/arash-mosavi/go-base-repository/pkg/types"
-	postgresDomain "github.com/arash-mosavi/postgrs-unit-of-work-system/pkg/domain"
-	postgresIdentifier "github.com/arash-mosavi/postgrs-unit-of-work-system/pkg/identifier"
-	postgresUOW "github.com/arash-mosavi/postgrs-unit-of-work-system/pkg/persistence"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// deletedAtColumn is the soft delete column maintained by gorm.DeletedAt
+const deletedAtColumn = "deleted_at"
+
 // BaseRepository implements the PostgreSQL base repository using composition
 type BaseRepository[T types.PostgresEntity] struct {
-	factory postgresUOW.IUnitOfWorkFactory[T]
+	db       *gorm.DB
+	settings settings
+	manual   *manualTx
 }
 
-// NewBaseRepository creates a new PostgreSQL base repository
-func NewBaseRepository[T types.PostgresEntity](factory postgresUOW.IUnitOfWorkFactory[T]) interfaces.PostgresBaseRepository[T] {
+// NewBaseRepository creates a new PostgreSQL base repository on top of db
+func NewBaseRepository[T types.PostgresEntity](db *gorm.DB, opts ...Option) interfaces.PostgresBaseRepository[T] {
 	return &BaseRepository[T]{
-		factory: factory,
+		db:       db,
+		settings: newSettings(opts),
+		manual:   &manualTx{},
 	}
 }
 
 // FindOneById finds an entity by its PostgreSQL integer ID
 func (r *BaseRepository[T]) FindOneById(ctx context.Context, id types.PostgresID) (T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.FindOneById(ctx, id)
+	var zero T
+
+	entity := newEntity[T]()
+	if err := r.conn(ctx).First(entity, id).Error; err != nil {
+		return zero, fmt.Errorf("failed to find by id: %w", translateError(err))
+	}
+	return entity, nil
 }
 
 // FindOne finds a single entity using identifier
 func (r *BaseRepository[T]) FindOne(ctx context.Context, filter types.Identifier) (T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-	return uow.FindOneByIdentifier(ctx, unifiedFilter.GetPostgresIdentifier())
+	var zero T
+
+	entity := newEntity[T]()
+	query, err := r.applyFilter(r.conn(ctx), filter)
+	if err != nil {
+		return zero, fmt.Errorf("failed to find by identifier: %w", err)
+	}
+	if err := query.First(entity).Error; err != nil {
+		return zero, fmt.Errorf("failed to find by identifier: %w", translateError(err))
+	}
+	return entity, nil
 }
 
 // FindAll finds all entities matching the identifier
 func (r *BaseRepository[T]) FindAll(ctx context.Context, filter types.Identifier) ([]T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.FindAll(ctx)
+	return r.FindAllOptions(ctx, filter, types.FindOptions{})
 }
 
-// FindAllWithPagination finds entities with pagination
-func (r *BaseRepository[T]) FindAllWithPagination(ctx context.Context, params types.QueryParams[T]) ([]T, int64, error) {
-	uow := r.factory.CreateWithContext(ctx)
+// FindAllOptions finds the entities matching the identifier, sorted, limited and projected as opts asks
+func (r *BaseRepository[T]) FindAllOptions(ctx context.Context, filter types.Identifier, opts types.FindOptions) ([]T, error) {
+	query, err := r.applyFilter(r.conn(ctx), filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find all: %w", err)
+	}
+
+	sort, err := r.orderBy(opts.Ordering())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find all: %w", err)
+	}
+	if sort != nil {
+		query = query.Order(clause.OrderBy{Expression: sort})
+	}
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+	if len(opts.Projection) > 0 {
+		fields, err := r.fields()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find all: %w", err)
+		}
+		projection, err := fields.Fields(opts.Projection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find all: %w", err)
+		}
+		query = query.Select(projection)
+	}
 
-	queryParams := postgresDomain.QueryParams[T]{
-		Filter: params.Filter,
-		Limit:  params.Limit,
-		Offset: params.Offset,
-		Sort:   convertSortMap(params.Sort),
+	var entities []T
+	if err := query.Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("failed to find all: %w", translateError(err))
 	}
+	return entities, nil
+}
 
-	entities, count, err := uow.FindAllWithPagination(ctx, queryParams)
-	return entities, int64(count), err
+// FindAllWithPagination finds entities with pagination
+func (r *BaseRepository[T]) FindAllWithPagination(ctx context.Context, params types.QueryParams[T]) ([]T, int64, error) {
+	return r.findWithPagination(r.conn(ctx), params)
 }
 
 // Insert creates a new entity
 func (r *BaseRepository[T]) Insert(ctx context.Context, entity T) (T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.Insert(ctx, entity)
+	if err := r.conn(ctx).Create(entity).Error; err != nil {
+		return entity, fmt.Errorf("failed to insert: %w", translateError(err))
+	}
+	return entity, nil
 }
 
-// Update modifies an existing entity
+// Update modifies an existing entity. The version of Versioned entities must match the stored one
+// and is incremented.
 func (r *BaseRepository[T]) Update(ctx context.Context, filter types.Identifier, entity T) (T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-	return uow.Update(ctx, unifiedFilter.GetPostgresIdentifier(), entity)
+	var zero T
+
+	db := r.conn(ctx)
+
+	current := newEntity[T]()
+	query, err := r.applyRequiredFilter(db, filter)
+	if err != nil {
+		return zero, fmt.Errorf("failed to update: %w", err)
+	}
+	if err := query.First(current).Error; err != nil {
+		return zero, fmt.Errorf("failed to update: %w", translateError(err))
+	}
+
+	update := db.Model(current)
+	versioned, isVersioned := interface{}(entity).(types.Versioned)
+	var expected int64
+	if isVersioned {
+		column, err := r.versionColumn()
+		if err != nil {
+			return entity, fmt.Errorf("failed to update: %w", err)
+		}
+		expected = versioned.GetVersion()
+		versioned.SetVersion(expected + 1)
+		update = update.Where(matchVersion(column, expected))
+	}
+
+	result := update.Updates(entity)
+	if result.Error != nil {
+		if isVersioned {
+			versioned.SetVersion(expected)
+		}
+		return entity, fmt.Errorf("failed to update: %w", translateError(result.Error))
+	}
+	if isVersioned && result.RowsAffected == 0 {
+		versioned.SetVersion(expected)
+		return entity, fmt.Errorf("failed to update: the stored version differs: %w", repoErrors.ErrConflict)
+	}
+
+	if err := db.First(current).Error; err != nil {
+		return entity, fmt.Errorf("failed to reload updated entity: %w", translateError(err))
+	}
+
+	return current, nil
 }
 
 // Delete removes an entity
 func (r *BaseRepository[T]) Delete(ctx context.Context, filter types.Identifier) error {
-	uow := r.factory.CreateWithContext(ctx)
-	unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-	return uow.Delete(ctx, unifiedFilter.GetPostgresIdentifier())
+	_, err := r.HardDelete(ctx, filter)
+	return err
 }
 
 // BulkInsert creates multiple entities
 func (r *BaseRepository[T]) BulkInsert(ctx context.Context, entities []T) ([]T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.BulkInsert(ctx, entities)
+	if len(entities) == 0 {
+		return entities, nil
+	}
+
+	if err := r.conn(ctx).Create(&entities).Error; err != nil {
+		return nil, fmt.Errorf("failed to bulk insert: %w", translateError(err))
+	}
+
+	return entities, nil
 }
 
 // BulkUpdate modifies multiple entities
 func (r *BaseRepository[T]) BulkUpdate(ctx context.Context, entities []T) ([]T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.BulkUpdate(ctx, entities)
+	if len(entities) == 0 {
+		return entities, nil
+	}
+
+	err := r.conn(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, entity := range entities {
+			result := tx.Model(entity).Updates(entity)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("entity %d was not updated: %w", entity.GetID(), repoErrors.ErrNotFound)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to bulk update: %w", translateError(err))
+	}
+
+	return entities, nil
 }
 
 // BulkDelete removes multiple entities
 func (r *BaseRepository[T]) BulkDelete(ctx context.Context, filters []types.Identifier) error {
-	uow := r.factory.CreateWithContext(ctx)
+	return r.BulkHardDelete(ctx, filters)
+}
+
+// SoftDelete marks an entity as deleted. For Versioned entities, an equality condition on the
+// version in filter is the version the row is expected to have rather than part of the match;
+// without one, the row must still have the version it was read with. The version is incremented,
+// and ErrConflict returned when the stored one differs.
+func (r *BaseRepository[T]) SoftDelete(ctx context.Context, filter types.Identifier) (T, error) {
+	var zero T
+
+	db := r.conn(ctx)
 
-	postgresFilters := make([]postgresIdentifier.IIdentifier, len(filters))
-	for i, filter := range filters {
-		unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-		postgresFilters[i] = unifiedFilter.GetPostgresIdentifier()
+	conditions, err := r.conditions(filter)
+	if err != nil {
+		return zero, fmt.Errorf("failed to soft delete: %w", err)
 	}
 
-	return uow.BulkHardDelete(ctx, postgresFilters)
-}
+	var column string
+	var expected int64
+	var checked bool
+	if r.versioned() {
+		fields, err := r.fields()
+		if err != nil {
+			return zero, fmt.Errorf("failed to soft delete: %w", err)
+		}
+		if column, err = fields.Version(); err != nil {
+			return zero, fmt.Errorf("failed to soft delete: %w", err)
+		}
+		if conditions, expected, checked, err = fields.ExpectedVersionCondition(conditions); err != nil {
+			return zero, fmt.Errorf("failed to soft delete: %w", err)
+		}
+	}
+	if err := requireConditions(conditions); err != nil {
+		return zero, fmt.Errorf("failed to soft delete: %w", err)
+	}
 
-// SoftDelete marks an entity as deleted
-func (r *BaseRepository[T]) SoftDelete(ctx context.Context, filter types.Identifier) (T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-	return uow.SoftDelete(ctx, unifiedFilter.GetPostgresIdentifier())
+	entity := newEntity[T]()
+	if err := where(db, conditions).First(entity).Error; err != nil {
+		return zero, fmt.Errorf("failed to soft delete: %w", translateError(err))
+	}
+
+	if versioned, ok := interface{}(entity).(types.Versioned); ok {
+		if !checked {
+			expected = versioned.GetVersion()
+		}
+
+		result := db.Model(entity).Where(matchVersion(column, expected)).Updates(map[string]interface{}{
+			deletedAtColumn: time.Now(),
+			column:          expected + 1,
+		})
+		if result.Error != nil {
+			return entity, fmt.Errorf("failed to soft delete: %w", translateError(result.Error))
+		}
+		if result.RowsAffected == 0 {
+			return entity, fmt.Errorf("failed to soft delete: the stored version differs: %w", repoErrors.ErrConflict)
+		}
+		versioned.SetVersion(expected + 1)
+		return entity, nil
+	}
+
+	if err := db.Delete(entity).Error; err != nil {
+		return entity, fmt.Errorf("failed to soft delete: %w", translateError(err))
+	}
+
+	return entity, nil
 }
 
 // HardDelete permanently removes an entity
 func (r *BaseRepository[T]) HardDelete(ctx context.Context, filter types.Identifier) (T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-	return uow.HardDelete(ctx, unifiedFilter.GetPostgresIdentifier())
+	var zero T
+
+	db := r.conn(ctx).Unscoped()
+
+	entity := newEntity[T]()
+	query, err := r.applyRequiredFilter(db, filter)
+	if err != nil {
+		return zero, fmt.Errorf("failed to hard delete: %w", err)
+	}
+	if err := query.First(entity).Error; err != nil {
+		return zero, fmt.Errorf("failed to hard delete: %w", translateError(err))
+	}
+
+	if err := db.Delete(entity).Error; err != nil {
+		return entity, fmt.Errorf("failed to hard delete: %w", translateError(err))
+	}
+
+	return entity, nil
 }
 
 // BulkSoftDelete marks multiple entities as deleted
 func (r *BaseRepository[T]) BulkSoftDelete(ctx context.Context, filters []types.Identifier) error {
-	uow := r.factory.CreateWithContext(ctx)
-
-	postgresFilters := make([]postgresIdentifier.IIdentifier, len(filters))
-	for i, filter := range filters {
-		unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-		postgresFilters[i] = unifiedFilter.GetPostgresIdentifier()
+	if err := r.bulkDelete(r.conn(ctx), filters); err != nil {
+		return fmt.Errorf("failed to bulk soft delete: %w", translateError(err))
 	}
-
-	return uow.BulkSoftDelete(ctx, postgresFilters)
+	return nil
 }
 
 // BulkHardDelete permanently removes multiple entities
 func (r *BaseRepository[T]) BulkHardDelete(ctx context.Context, filters []types.Identifier) error {
-	uow := r.factory.CreateWithContext(ctx)
-
-	postgresFilters := make([]postgresIdentifier.IIdentifier, len(filters))
-	for i, filter := range filters {
-		unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-		postgresFilters[i] = unifiedFilter.GetPostgresIdentifier()
+	if err := r.bulkDelete(r.conn(ctx).Unscoped(), filters); err != nil {
+		return fmt.Errorf("failed to bulk hard delete: %w", translateError(err))
 	}
-
-	return uow.BulkHardDelete(ctx, postgresFilters)
+	return nil
 }
 
 // GetTrashed retrieves all soft-deleted entities
 func (r *BaseRepository[T]) GetTrashed(ctx context.Context) ([]T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.GetTrashed(ctx)
+	var entities []T
+	if err := r.trashed(r.conn(ctx)).Find(&entities).Error; err != nil {
+		return nil, fmt.Errorf("failed to get trashed: %w", translateError(err))
+	}
+	return entities, nil
 }
 
 // GetTrashedWithPagination retrieves soft-deleted entities with pagination
 func (r *BaseRepository[T]) GetTrashedWithPagination(ctx context.Context, params types.QueryParams[T]) ([]T, int64, error) {
-	uow := r.factory.CreateWithContext(ctx)
-
-	queryParams := postgresDomain.QueryParams[T]{
-		Filter: params.Filter,
-		Limit:  params.Limit,
-		Offset: params.Offset,
-		Sort:   convertSortMap(params.Sort),
-	}
-
-	entities, count, err := uow.GetTrashedWithPagination(ctx, queryParams)
-	return entities, int64(count), err
+	return r.findWithPagination(r.trashed(r.conn(ctx)), params)
 }
 
 // Restore recovers a soft-deleted entity
 func (r *BaseRepository[T]) Restore(ctx context.Context, filter types.Identifier) (T, error) {
-	uow := r.factory.CreateWithContext(ctx)
-	unifiedFilter := filter.(*identifier.UnifiedIdentifier)
-	return uow.Restore(ctx, unifiedFilter.GetPostgresIdentifier())
+	var zero T
+
+	db := r.trashed(r.conn(ctx))
+
+	entity := newEntity[T]()
+	query, err := r.applyFilter(db, filter)
+	if err != nil {
+		return zero, fmt.Errorf("failed to restore: %w", err)
+	}
+	if err := query.First(entity).Error; err != nil {
+		return zero, fmt.Errorf("failed to restore: %w", translateError(err))
+	}
+
+	if err := r.conn(ctx).Unscoped().Model(entity).Update(deletedAtColumn, nil).Error; err != nil {
+		return entity, fmt.Errorf("failed to restore: %w", translateError(err))
+	}
+
+	return entity, nil
 }
 
 // RestoreAll recovers all soft-deleted entities
 func (r *BaseRepository[T]) RestoreAll(ctx context.Context) error {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.RestoreAll(ctx)
+	if err := r.trashed(r.conn(ctx)).Update(deletedAtColumn, nil).Error; err != nil {
+		return fmt.Errorf("failed to restore all: %w", translateError(err))
+	}
+	return nil
 }
 
-// BeginTransaction starts a database transaction
-func (r *BaseRepository[T]) BeginTransaction(ctx context.Context) error {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.BeginTransaction(ctx)
+// conn returns the handle for ctx, preferring the transaction bound by WithTransaction and then
+// the one begun by BeginTransaction
+func (r *BaseRepository[T]) conn(ctx context.Context) *gorm.DB {
+	if tx, ok := r.transaction(ctx); ok {
+		return tx.WithContext(ctx)
+	}
+	return r.db.WithContext(ctx)
 }
 
-// CommitTransaction commits the current transaction
-func (r *BaseRepository[T]) CommitTransaction(ctx context.Context) error {
-	uow := r.factory.CreateWithContext(ctx)
-	return uow.CommitTransaction(ctx)
+// trashed scopes db to the soft-deleted rows of T
+func (r *BaseRepository[T]) trashed(db *gorm.DB) *gorm.DB {
+	return db.Unscoped().Model(newEntity[T]()).Where(clause.Expr{
+		SQL:  "? IS NOT NULL",
+		Vars: []interface{}{clause.Column{Name: deletedAtColumn}},
+	})
 }
 
-// RollbackTransaction rolls back the current transaction
-func (r *BaseRepository[T]) RollbackTransaction(ctx context.Context) error {
-	uow := r.factory.CreateWithContext(ctx)
-	uow.RollbackTransaction(ctx)
-	return nil
-}
+// bulkDelete deletes the rows matched by each filter in a single transaction
+func (r *BaseRepository[T]) bulkDelete(db *gorm.DB, filters []types.Identifier) error {
+	if len(filters) == 0 {
+		return nil
+	}
 
-// convertSortMap converts unified sort map to PostgreSQL sort map
-func convertSortMap(sort types.SortMap) postgresDomain.SortMap {
-	if sort == nil {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, filter := range filters {
+			query, err := r.applyRequiredFilter(tx, filter)
+			if err != nil {
+				return err
+			}
+			if err := query.Delete(newEntity[T]()).Error; err != nil {
+				return err
+			}
+		}
 		return nil
+	})
+}
+
+// findWithPagination counts the rows selected by db and returns the requested page of them
+func (r *BaseRepository[T]) findWithPagination(db *gorm.DB, params types.QueryParams[T]) ([]T, int64, error) {
+	query, err := r.applyFilter(db.Model(newEntity[T]()), params.Where)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find with pagination: %w", err)
+	}
+	sort, err := r.orderBy(params.Ordering())
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find with pagination: %w", err)
+	}
+	if !isZero(params.Filter) {
+		query = query.Where(params.Filter)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count records: %w", translateError(err))
+	}
+
+	if params.Limit > 0 {
+		query = query.Limit(params.Limit)
+	}
+	if params.Offset > 0 {
+		query = query.Offset(params.Offset)
+	}
+	if sort != nil {
+		query = query.Order(clause.OrderBy{Expression: sort})
+	}
+
+	var entities []T
+	if err := query.Find(&entities).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to find with pagination: %w", translateError(err))
 	}
 
-	postgresSort := make(postgresDomain.SortMap)
-	for field, direction := range sort {
-		switch direction {
+	return entities, total, nil
+}
+
+// orderBy resolves the fields of sort against the columns of T and converts it to an ORDER BY expression
+func (r *BaseRepository[T]) orderBy(sort types.SortSpec) (clause.Expression, error) {
+	fields, err := r.fields()
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := fields.Sort(sort)
+	if err != nil {
+		return nil, err
+	}
+	return convertSortMap(resolved)
+}
+
+// convertSortMap converts a sort specification to a PostgreSQL ORDER BY expression, or nil when it is empty
+func convertSortMap(sort types.SortSpec) (clause.Expression, error) {
+	if len(sort) == 0 {
+		return nil, nil
+	}
+
+	terms := make([]string, 0, len(sort))
+	vars := make([]interface{}, 0, len(sort))
+	for _, field := range sort {
+		if field.Field == "" {
+			return nil, fmt.Errorf("%w: sort field without a name", repoErrors.ErrInvalidFilter)
+		}
+
+		term := "?"
+		switch field.Direction {
 		case types.SortAsc:
-			postgresSort[field] = postgresDomain.SortAsc
+			term += " ASC"
 		case types.SortDesc:
-			postgresSort[field] = postgresDomain.SortDesc
+			term += " DESC"
+		default:
+			return nil, fmt.Errorf("%w: unknown sort direction %q for field %q", repoErrors.ErrInvalidFilter, field.Direction, field.Field)
 		}
+
+		switch field.Nulls {
+		case types.NullsDefault:
+		case types.NullsFirst, types.NullsLast:
+			term += " NULLS " + string(field.Nulls)
+		default:
+			return nil, fmt.Errorf("%w: unknown nulls order %q for field %q", repoErrors.ErrInvalidFilter, field.Nulls, field.Field)
+		}
+
+		terms = append(terms, term)
+		vars = append(vars, clause.Column{Name: field.Field})
 	}
 
-	return postgresSort
+	return clause.Expr{SQL: strings.Join(terms, ", "), Vars: vars}, nil
+}
+
+// newEntity allocates a zero entity that GORM can scan into
+func newEntity[T types.PostgresEntity]() T {
+	var zero T
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() == reflect.Ptr {
+		return reflect.New(t.Elem()).Interface().(T)
+	}
+	return zero
+}
+
+// isZero reports whether v is nil or the zero value of its type
+func isZero(v interface{}) bool {
+	rv := reflect.ValueOf(v)
+	return !rv.IsValid() || rv.IsZero()
 }
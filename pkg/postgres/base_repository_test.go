@@ -0,0 +1,232 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arash-mosavi/go-base-repository/internal/fakesql"
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/interfaces"
+	"github.com/arash-mosavi/go-base-repository/pkg/postgres"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"gorm.io/gorm"
+)
+
+// User is an entity without optimistic locking
+type User struct {
+	ID        int            `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string         `gorm:"type:varchar(255);not null" json:"name"`
+	Email     string         `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
+	Slug      string         `gorm:"type:varchar(255);uniqueIndex;not null" json:"slug"`
+	Logins    int            `json:"logins"`
+	Tags      []string       `gorm:"type:text[]" json:"tags"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+}
+
+func (u *User) GetID() int                    { return u.ID }
+func (u *User) GetSlug() string               { return u.Slug }
+func (u *User) SetSlug(slug string)           { u.Slug = slug }
+func (u *User) GetCreatedAt() time.Time       { return u.CreatedAt }
+func (u *User) GetUpdatedAt() time.Time       { return u.UpdatedAt }
+func (u *User) GetArchivedAt() gorm.DeletedAt { return u.DeletedAt }
+func (u *User) GetName() string               { return u.Name }
+
+// Account is an entity with optimistic locking
+type Account struct {
+	ID        int            `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string         `json:"name"`
+	Slug      string         `json:"slug"`
+	Version   int64          `json:"version"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+}
+
+func (a *Account) GetID() int                    { return a.ID }
+func (a *Account) GetSlug() string               { return a.Slug }
+func (a *Account) SetSlug(slug string)           { a.Slug = slug }
+func (a *Account) GetCreatedAt() time.Time       { return a.CreatedAt }
+func (a *Account) GetUpdatedAt() time.Time       { return a.UpdatedAt }
+func (a *Account) GetArchivedAt() gorm.DeletedAt { return a.DeletedAt }
+func (a *Account) GetName() string               { return a.Name }
+func (a *Account) GetVersion() int64             { return a.Version }
+func (a *Account) SetVersion(version int64)      { a.Version = version }
+
+func TestSingleWrites_RequireFilter(t *testing.T) {
+	writes := map[string]func(repo interfaces.PostgresBaseRepository[*User], filter types.Identifier) error{
+		"Update": func(repo interfaces.PostgresBaseRepository[*User], filter types.Identifier) error {
+			_, err := repo.Update(context.Background(), filter, &User{Name: "John"})
+			return err
+		},
+		"Patch": func(repo interfaces.PostgresBaseRepository[*User], filter types.Identifier) error {
+			_, err := repo.Patch(context.Background(), filter, types.NewUpdateSet().Set("name", "John"))
+			return err
+		},
+		"Delete": func(repo interfaces.PostgresBaseRepository[*User], filter types.Identifier) error {
+			return repo.Delete(context.Background(), filter)
+		},
+		"SoftDelete": func(repo interfaces.PostgresBaseRepository[*User], filter types.Identifier) error {
+			_, err := repo.SoftDelete(context.Background(), filter)
+			return err
+		},
+		"HardDelete": func(repo interfaces.PostgresBaseRepository[*User], filter types.Identifier) error {
+			_, err := repo.HardDelete(context.Background(), filter)
+			return err
+		},
+		"BulkSoftDelete": func(repo interfaces.PostgresBaseRepository[*User], filter types.Identifier) error {
+			return repo.BulkSoftDelete(context.Background(), []types.Identifier{filter})
+		},
+		"BulkHardDelete": func(repo interfaces.PostgresBaseRepository[*User], filter types.Identifier) error {
+			return repo.BulkHardDelete(context.Background(), []types.Identifier{filter})
+		},
+	}
+
+	for name, write := range writes {
+		for _, filter := range []types.Identifier{nil, identifier.New()} {
+			t.Run(name, func(t *testing.T) {
+				db, fake := fakesql.Open(t)
+				repo := postgres.NewBaseRepository[*User](db)
+
+				if err := write(repo, filter); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+					t.Errorf("Expected ErrInvalidFilter, got %v", err)
+				}
+				for _, sql := range fake.SQL() {
+					if !strings.HasPrefix(sql, "BEGIN") && !strings.HasPrefix(sql, "ROLLBACK") {
+						t.Errorf("Expected no statements, got %s", sql)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestFindAllWithPagination_Where(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+	fake.Expect("SELECT count", fakesql.Result{Columns: []string{"count"}, Rows: [][]driver.Value{{int64(42)}}})
+	fake.Expect("SELECT", users(1, 2))
+
+	page, total, err := repo.FindAllWithPagination(context.Background(), types.QueryParams[*User]{
+		Filter:  &User{Name: "John"},
+		Where:   identifier.New().GreaterThan("logins", 3).In("slug", []interface{}{"a", "b"}),
+		Limit:   10,
+		Offset:  20,
+		OrderBy: types.SortSpec{{Field: "created_at", Direction: types.SortDesc}, {Field: "id", Direction: types.SortAsc}},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(page) != 2 || total != 42 {
+		t.Errorf("Expected 2 users of 42, got %d of %d", len(page), total)
+	}
+
+	where := `WHERE "logins" > $1 AND "slug" IN ($2,$3) AND "users"."name" = $4 AND "users"."deleted_at" IS NULL`
+	expected := []string{
+		`SELECT count(*) FROM "users" ` + where,
+		`SELECT * FROM "users" ` + where + ` ORDER BY "created_at" DESC, "id" ASC LIMIT $5 OFFSET $6`,
+	}
+	if statements := fake.SQL(); !reflect.DeepEqual(statements, expected) {
+		t.Errorf("Expected %v, got %v", expected, statements)
+	}
+	if args := fake.Statements()[0].Args; !containsValue(args, 3) || !containsValue(args, "John") {
+		t.Errorf("Expected the count to bind the filter, got %v", args)
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	tests := map[string]struct {
+		filter   types.Identifier
+		expected string
+	}{
+		"nil":    {nil, `SELECT * FROM "users" WHERE "users"."deleted_at" IS NULL`},
+		"filter": {identifier.New().Equal("name", "John").GreaterThan("logins", 1), `SELECT * FROM "users" WHERE "logins" > $1 AND "name" = $2 AND "users"."deleted_at" IS NULL`},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fake := fakesql.Open(t)
+			repo := postgres.NewBaseRepository[*User](db)
+			fake.Expect("SELECT", users(1, 2))
+
+			found, err := repo.FindAll(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(found) != 2 {
+				t.Errorf("Expected 2 users, got %d", len(found))
+			}
+			if statements := fake.SQL(); !reflect.DeepEqual(statements, []string{tt.expected}) {
+				t.Errorf("Expected %s, got %v", tt.expected, statements)
+			}
+		})
+	}
+}
+
+func TestFindAllOptions(t *testing.T) {
+	tests := map[string]struct {
+		opts     types.FindOptions
+		expected string
+	}{
+		"order by": {
+			types.FindOptions{
+				OrderBy: types.SortSpec{
+					{Field: "created_at", Direction: types.SortDesc, Nulls: types.NullsLast},
+					{Field: "name", Direction: types.SortAsc, Nulls: types.NullsFirst},
+					{Field: "id", Direction: types.SortAsc},
+				},
+				Limit:      5,
+				Projection: []string{"id", "name"},
+			},
+			`SELECT "id","name" FROM "users" WHERE "name" = $1 AND "users"."deleted_at" IS NULL ORDER BY "created_at" DESC NULLS LAST, "name" ASC NULLS FIRST, "id" ASC LIMIT $2`,
+		},
+		"sort map": {
+			types.FindOptions{Sort: types.SortMap{"name": types.SortDesc, "created_at": types.SortAsc}},
+			`SELECT * FROM "users" WHERE "name" = $1 AND "users"."deleted_at" IS NULL ORDER BY "created_at" ASC, "name" DESC`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fake := fakesql.Open(t)
+			repo := postgres.NewBaseRepository[*User](db)
+
+			if _, err := repo.FindAllOptions(context.Background(), identifier.New().Equal("name", "John"), tt.opts); err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if statements := fake.SQL(); !reflect.DeepEqual(statements, []string{tt.expected}) {
+				t.Errorf("Expected %s, got %v", tt.expected, statements)
+			}
+		})
+	}
+}
+
+func TestFindAllOptions_Invalid(t *testing.T) {
+	tests := map[string]types.FindOptions{
+		"unknown sort field":  {OrderBy: types.SortSpec{{Field: "nickname", Direction: types.SortAsc}}},
+		"unknown direction":   {OrderBy: types.SortSpec{{Field: "name", Direction: "sideways"}}},
+		"unknown nulls order": {OrderBy: types.SortSpec{{Field: "name", Direction: types.SortAsc, Nulls: "middle"}}},
+		"unknown projection":  {Projection: []string{"nickname"}},
+	}
+
+	for name, opts := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, fake := fakesql.Open(t)
+			repo := postgres.NewBaseRepository[*User](db)
+
+			if _, err := repo.FindAllOptions(context.Background(), nil, opts); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+				t.Errorf("Expected ErrInvalidFilter, got %v", err)
+			}
+			if statements := fake.SQL(); len(statements) != 0 {
+				t.Errorf("Expected no statements, got %v", statements)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"errors"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// SQLSTATE codes translated to repository errors
+const (
+	uniqueViolationCode     = "23505"
+	inFailedTransactionCode = "25P02"
+)
+
+// translateError maps GORM and PostgreSQL errors to the repository errors, keeping err as the cause.
+// Errors that are already translated or have no repository equivalent are returned unchanged.
+func translateError(err error) error {
+	var repoErr *repoErrors.Error
+	if err == nil || errors.As(err, &repoErr) {
+		return err
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return repoErrors.Wrap(repoErrors.ErrNotFound, err)
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return repoErrors.Wrap(repoErrors.ErrDuplicateKey, err)
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case uniqueViolationCode:
+		return repoErrors.Wrap(repoErrors.ErrDuplicateKey, err)
+	case serializationFailureCode:
+		return repoErrors.Wrap(repoErrors.ErrConflict, err)
+	case deadlockDetectedCode, inFailedTransactionCode:
+		return repoErrors.Wrap(repoErrors.ErrTxAborted, err)
+	}
+
+	return err
+}
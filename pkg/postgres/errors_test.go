@@ -0,0 +1,50 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/arash-mosavi/go-base-repository/internal/fakesql"
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/postgres"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestFindOneById_NotFound(t *testing.T) {
+	db, _ := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+
+	_, err := repo.FindOneById(context.Background(), 1)
+	if !errors.Is(err, repoErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected the GORM error to be kept, got %v", err)
+	}
+}
+
+func TestInsert_DuplicateKey(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+	violation := &pgconn.PgError{Code: "23505", ConstraintName: "idx_users_email"}
+	fake.Expect("INSERT", fakesql.Result{Err: violation})
+
+	_, err := repo.Insert(context.Background(), &User{Name: "John", Email: "john@example.com"})
+	if !errors.Is(err, repoErrors.ErrDuplicateKey) {
+		t.Fatalf("Expected ErrDuplicateKey, got %v", err)
+	}
+
+	var repoErr *repoErrors.Error
+	if !errors.As(err, &repoErr) {
+		t.Fatalf("Expected a repository error, got %T", err)
+	}
+	if cause := errors.Unwrap(repoErr); cause != violation {
+		t.Errorf("Expected the driver error as the cause, got %v", cause)
+	}
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.ConstraintName != "idx_users_email" {
+		t.Errorf("Expected the violated constraint to be reachable, got %v", err)
+	}
+}
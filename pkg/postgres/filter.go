@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"fmt"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"gorm.io/gorm"
+)
+
+// applyFilter adds a WHERE condition to db for every condition of filter over the columns of T.
+// A nil filter leaves db unchanged; unusable filters and fields T does not have are reported
+// as ErrInvalidFilter.
+func (r *BaseRepository[T]) applyFilter(db *gorm.DB, filter types.Identifier) (*gorm.DB, error) {
+	conditions, err := r.conditions(filter)
+	if err != nil {
+		return nil, err
+	}
+	return where(db, conditions), nil
+}
+
+// applyRequiredFilter is applyFilter for writes meant for a single row: a filter without
+// conditions is reported as ErrInvalidFilter instead of matching an arbitrary row
+func (r *BaseRepository[T]) applyRequiredFilter(db *gorm.DB, filter types.Identifier) (*gorm.DB, error) {
+	conditions, err := r.conditions(filter)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireConditions(conditions); err != nil {
+		return nil, err
+	}
+	return where(db, conditions), nil
+}
+
+// manyConditions returns the conditions of filter for writes changing every matching row. A
+// filter without conditions is reported as ErrInvalidFilter unless it is identifier.All().
+func (r *BaseRepository[T]) manyConditions(filter types.Identifier) ([]identifier.Condition, error) {
+	conditions, err := r.conditions(filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(conditions) == 0 && !identifier.MatchesAll(filter) {
+		return nil, fmt.Errorf("%w: a filter is required, use identifier.All() to match every row", repoErrors.ErrInvalidFilter)
+	}
+	return conditions, nil
+}
+
+// conditions returns the conditions of filter with their fields resolved to the columns of T
+func (r *BaseRepository[T]) conditions(filter types.Identifier) ([]identifier.Condition, error) {
+	conditions, err := identifier.Conditions(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := r.fields()
+	if err != nil {
+		return nil, err
+	}
+	return fields.Conditions(conditions)
+}
+
+// where adds a WHERE condition to db for every one of conditions
+func where(db *gorm.DB, conditions []identifier.Condition) *gorm.DB {
+	for _, expression := range identifier.Expressions(conditions) {
+		db = db.Where(expression)
+	}
+	return db
+}
+
+// requireConditions reports ErrInvalidFilter when there are no conditions
+func requireConditions(conditions []identifier.Condition) error {
+	if len(conditions) == 0 {
+		return fmt.Errorf("%w: a filter is required", repoErrors.ErrInvalidFilter)
+	}
+	return nil
+}
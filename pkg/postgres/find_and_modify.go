@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FindOneAndUpdate applies changes, as Patch does, to the first live row matching filter in the
+// order of opts.OrderBy, and returns it as it was before or after the update. The row is selected
+// FOR UPDATE SKIP LOCKED, so concurrent callers claim different rows instead of waiting for each
+// other, and updated with UPDATE ... RETURNING.
+func (r *BaseRepository[T]) FindOneAndUpdate(ctx context.Context, filter types.Identifier, changes map[string]interface{}, opts types.FindAndModifyOptions) (T, error) {
+	var zero T
+
+	assignments, err := r.assignments(changes)
+	if err != nil {
+		return zero, fmt.Errorf("failed to find and update: %w", err)
+	}
+
+	db := r.conn(ctx)
+
+	if opts.Return != types.ReturnBefore {
+		selected, err := r.selectForUpdate(db, filter, opts.OrderBy)
+		if err != nil {
+			return zero, fmt.Errorf("failed to find and update: %w", err)
+		}
+
+		updated := newEntity[T]()
+		if err := r.updateReturning(db, updated, selected, assignments); err != nil {
+			return zero, fmt.Errorf("failed to find and update: %w", err)
+		}
+		return updated, nil
+	}
+
+	// the old values are gone once the row is updated, so read them in the same transaction first
+	current := newEntity[T]()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		selected, err := r.selectForUpdate(tx, filter, opts.OrderBy)
+		if err != nil {
+			return err
+		}
+		if err := tx.Where(selected).Take(current).Error; err != nil {
+			return translateError(err)
+		}
+
+		s, err := r.schema()
+		if err != nil {
+			return err
+		}
+		byID := clause.Eq{Column: clause.Column{Name: primaryKey(s)}, Value: current.GetID()}
+		return r.updateReturning(tx, newEntity[T](), byID, assignments)
+	})
+	if err != nil {
+		return zero, fmt.Errorf("failed to find and update: %w", err)
+	}
+
+	return current, nil
+}
+
+// FindOneAndDelete permanently removes the first row matching filter in the order of
+// opts.OrderBy and returns it. The row is selected FOR UPDATE SKIP LOCKED and removed with
+// DELETE ... RETURNING in a single statement.
+func (r *BaseRepository[T]) FindOneAndDelete(ctx context.Context, filter types.Identifier, opts types.FindAndModifyOptions) (T, error) {
+	var zero T
+
+	db := r.conn(ctx).Unscoped()
+
+	selected, err := r.selectForUpdate(db, filter, opts.OrderBy)
+	if err != nil {
+		return zero, fmt.Errorf("failed to find and delete: %w", err)
+	}
+
+	entity := newEntity[T]()
+	result := db.Clauses(clause.Returning{}).Where(selected).Delete(entity)
+	if result.Error != nil {
+		return zero, fmt.Errorf("failed to find and delete: %w", translateError(result.Error))
+	}
+	if result.RowsAffected == 0 {
+		return zero, fmt.Errorf("failed to find and delete: %w", repoErrors.ErrNotFound)
+	}
+
+	return entity, nil
+}
+
+// selectForUpdate returns the condition matching the first row of db matching filter in the
+// order of sort, locked with FOR UPDATE SKIP LOCKED when the statement runs
+func (r *BaseRepository[T]) selectForUpdate(db *gorm.DB, filter types.Identifier, sort types.SortSpec) (clause.Expression, error) {
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+	column := clause.Column{Name: primaryKey(s)}
+
+	query := db.Session(&gorm.Session{NewDB: true}).Model(newEntity[T]())
+	if db.Statement.Unscoped {
+		query = query.Unscoped()
+	}
+	query, err = r.applyFilter(query, filter)
+	if err != nil {
+		return nil, err
+	}
+	orderBy, err := r.orderBy(sort)
+	if err != nil {
+		return nil, err
+	}
+	if orderBy != nil {
+		query = query.Order(clause.OrderBy{Expression: orderBy})
+	}
+	query = query.Select(column.Name).Limit(1).Clauses(clause.Locking{
+		Strength: clause.LockingStrengthUpdate,
+		Options:  clause.LockingOptionsSkipLocked,
+	})
+	if query.Error != nil {
+		return nil, query.Error
+	}
+
+	return clause.Expr{SQL: "? IN (?)", Vars: []interface{}{column, query}}, nil
+}
+
+// updateReturning applies assignments to the live row matching condition and scans the updated
+// row into entity, reporting ErrNotFound when no row matched
+func (r *BaseRepository[T]) updateReturning(db *gorm.DB, entity T, condition clause.Expression, assignments map[string]interface{}) error {
+	result := db.Model(entity).Clauses(clause.Returning{}).Where(condition).Updates(assignments)
+	if result.Error != nil {
+		return translateError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return repoErrors.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arash-mosavi/go-base-repository/internal/fakesql"
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/postgres"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+)
+
+// oldestFirst picks the earliest created entity
+var oldestFirst = types.FindAndModifyOptions{OrderBy: types.SortSpec{{Field: "created_at", Direction: types.SortAsc}}}
+
+func TestFindOneAndUpdate(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+	fake.Expect("UPDATE", users(1))
+
+	user, err := repo.FindOneAndUpdate(context.Background(), identifier.New().Equal("name", "John"), types.NewUpdateSet().Set("slug", "claimed"), oldestFirst)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user.ID != 1 {
+		t.Errorf("Expected the updated row, got %v", user)
+	}
+
+	sql := fake.SQL()
+	if len(sql) != 1 {
+		t.Fatalf("Expected a single statement, got %v", sql)
+	}
+	expected := `UPDATE "users" SET "slug"=$1,"updated_at"=$2 WHERE "id" IN (SELECT "id" FROM "users" WHERE "name" = $3 AND "users"."deleted_at" IS NULL ORDER BY "created_at" ASC LIMIT $4 FOR UPDATE SKIP LOCKED) AND "users"."deleted_at" IS NULL RETURNING *`
+	if sql[0] != expected {
+		t.Errorf("Expected %s, got %s", expected, sql[0])
+	}
+}
+
+func TestFindOneAndUpdate_ReturnBefore(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+	fake.Expect("SELECT", users(1))
+	fake.Expect("UPDATE", users(1))
+
+	opts := oldestFirst
+	opts.Return = types.ReturnBefore
+	user, err := repo.FindOneAndUpdate(context.Background(), identifier.New().Equal("name", "John"), types.NewUpdateSet().Set("slug", "claimed"), opts)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user.ID != 1 {
+		t.Errorf("Expected the row as it was, got %v", user)
+	}
+
+	expected := []string{"BEGIN", "SELECT", "UPDATE", "COMMIT"}
+	if got := verbs(fake); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	sql := fake.SQL()
+	if !strings.Contains(sql[1], "FOR UPDATE SKIP LOCKED") {
+		t.Errorf("Expected the row to be locked, got %s", sql[1])
+	}
+	if !strings.Contains(sql[2], `WHERE "id" = $3`) || !strings.HasSuffix(sql[2], "RETURNING *") {
+		t.Errorf("Expected the locked row to be updated, got %s", sql[2])
+	}
+}
+
+func TestFindOneAndUpdate_NotFound(t *testing.T) {
+	db, _ := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+
+	_, err := repo.FindOneAndUpdate(context.Background(), identifier.New().Equal("name", "John"), types.NewUpdateSet().Set("slug", "claimed"), types.FindAndModifyOptions{})
+	if !errors.Is(err, repoErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestFindOneAndDelete(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+	fake.Expect("DELETE", users(1))
+
+	user, err := repo.FindOneAndDelete(context.Background(), identifier.New().Equal("name", "John"), oldestFirst)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user.ID != 1 {
+		t.Errorf("Expected the deleted row, got %v", user)
+	}
+
+	sql := fake.SQL()
+	if len(sql) != 1 {
+		t.Fatalf("Expected a single statement, got %v", sql)
+	}
+	expected := `DELETE FROM "users" WHERE "id" IN (SELECT "id" FROM "users" WHERE "name" = $1 ORDER BY "created_at" ASC LIMIT $2 FOR UPDATE SKIP LOCKED) RETURNING *`
+	if sql[0] != expected {
+		t.Errorf("Expected %s, got %s", expected, sql[0])
+	}
+}
+
+func TestFindOneAndDelete_NotFound(t *testing.T) {
+	db, _ := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+
+	_, err := repo.FindOneAndDelete(context.Background(), identifier.New().Equal("name", "John"), types.FindAndModifyOptions{})
+	if !errors.Is(err, repoErrors.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/cursor"
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	repoSchema "github.com/arash-mosavi/go-base-repository/pkg/schema"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+func init() {
+	// sort key values are gob-encoded as interface values
+	gob.Register(time.Time{})
+}
+
+// FindPage returns one page of the entities matching req.Where, paginated by the sort key
+// plus the primary key rather than by offset, so pages stay stable under concurrent inserts
+func (r *BaseRepository[T]) FindPage(ctx context.Context, req types.PageRequest) (types.Page[T], error) {
+	var page types.Page[T]
+
+	entitySchema, err := r.schema()
+	if err != nil {
+		return page, fmt.Errorf("failed to find page: %w", err)
+	}
+
+	query, err := r.applyFilter(r.conn(ctx), req.Where)
+	if err != nil {
+		return page, fmt.Errorf("failed to find page: %w", err)
+	}
+
+	orderBy, err := repoSchema.FromGORM(entitySchema).Sort(req.OrderBy)
+	if err != nil {
+		return page, fmt.Errorf("failed to find page: %w", err)
+	}
+
+	// PostgreSQL orders nulls after all other values
+	sort := cursor.Keyset(orderBy, primaryKey(entitySchema), types.NullsLast)
+	fingerprint, err := cursor.Fingerprint(req.Where, sort)
+	if err != nil {
+		return page, fmt.Errorf("failed to find page: %w", err)
+	}
+
+	current, err := cursor.Parse(r.settings.cursorKey, req.Cursor, fingerprint)
+	if err != nil {
+		return page, fmt.Errorf("failed to find page: %w", err)
+	}
+
+	order := sort
+	if current != nil {
+		if current.Backward {
+			order = cursor.Reverse(sort)
+		}
+
+		after, err := keysetCondition(order, current.Key)
+		if err != nil {
+			return page, fmt.Errorf("failed to find page: %w", err)
+		}
+		query = query.Where(after)
+	}
+
+	orderExpression, err := convertSortMap(order)
+	if err != nil {
+		return page, fmt.Errorf("failed to find page: %w", err)
+	}
+
+	limit := req.PageSize()
+	var rows []T
+	if err := query.Order(clause.OrderBy{Expression: orderExpression}).Limit(limit + 1).Find(&rows).Error; err != nil {
+		return page, fmt.Errorf("failed to find page: %w", translateError(err))
+	}
+
+	return cursor.BuildPage(r.settings.cursorKey, fingerprint, current, rows, limit, func(entity T) ([]byte, error) {
+		return sortKey(ctx, entitySchema, entity, sort)
+	})
+}
+
+// schema returns the parsed GORM schema of T
+func (r *BaseRepository[T]) schema() (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: r.db}
+	if err := stmt.Parse(newEntity[T]()); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return stmt.Schema, nil
+}
+
+// fields returns the queryable columns of T
+func (r *BaseRepository[T]) fields() (*repoSchema.Schema, error) {
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+	return repoSchema.FromGORM(s), nil
+}
+
+// primaryKey returns the primary key column of s, used as the keyset pagination tiebreaker
+func primaryKey(s *schema.Schema) string {
+	if s.PrioritizedPrimaryField != nil {
+		return s.PrioritizedPrimaryField.DBName
+	}
+	return "id"
+}
+
+// sortKey encodes the values of the sort columns of entity as driver values
+func sortKey(ctx context.Context, s *schema.Schema, entity interface{}, sort types.SortSpec) ([]byte, error) {
+	row := reflect.Indirect(reflect.ValueOf(entity))
+
+	values := make([]interface{}, len(sort))
+	for i, field := range sort {
+		schemaField := s.LookUpField(field.Field)
+		if schemaField == nil {
+			return nil, fmt.Errorf("%w: unknown sort field %q", repoErrors.ErrInvalidFilter, field.Field)
+		}
+
+		value, _ := schemaField.ValueOf(ctx, row)
+		driverValue, err := driver.DefaultParameterConverter.ConvertValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("%w: sort field %q: %v", repoErrors.ErrInvalidFilter, field.Field, err)
+		}
+		values[i] = driverValue
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(values); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// keysetCondition matches the rows that come after the position encoded in key when
+// ordered by sort: those ordered after it on the first column, or equal on the first
+// column and after it on the second, and so on
+func keysetCondition(sort types.SortSpec, key []byte) (clause.Expression, error) {
+	var values []interface{}
+	if err := gob.NewDecoder(bytes.NewReader(key)).Decode(&values); err != nil || len(values) != len(sort) {
+		return nil, fmt.Errorf("%w: cursor does not match the sort order", repoErrors.ErrInvalidFilter)
+	}
+
+	var branches []string
+	var vars []interface{}
+	var equal []string
+	var equalVars []interface{}
+	for i, field := range sort {
+		column := clause.Column{Name: field.Field}
+		value := values[i]
+
+		if sql, afterVars := afterValue(field, column, value); sql != "" {
+			branches = append(branches, "("+strings.Join(append(append([]string{}, equal...), sql), " AND ")+")")
+			vars = append(append(vars, equalVars...), afterVars...)
+		}
+
+		if value == nil {
+			equal = append(equal, "? IS NULL")
+			equalVars = append(equalVars, column)
+		} else {
+			equal = append(equal, "? = ?")
+			equalVars = append(equalVars, column, value)
+		}
+	}
+
+	if len(branches) == 0 {
+		// nothing sorts after the position
+		return clause.Expr{SQL: "1 = 0"}, nil
+	}
+
+	return clause.Expr{SQL: "(" + strings.Join(branches, " OR ") + ")", Vars: vars}, nil
+}
+
+// afterValue renders the condition matching the values of column ordered after value,
+// or an empty string when there are none. Comparisons never match NULL, so nulls are
+// handled according to the NULLS placement of field.
+func afterValue(field types.SortField, column clause.Column, value interface{}) (string, []interface{}) {
+	nullsLast := field.Nulls == types.NullsLast
+
+	if value == nil {
+		if nullsLast {
+			return "", nil
+		}
+		return "? IS NOT NULL", []interface{}{column}
+	}
+
+	operator := ">"
+	if field.Direction == types.SortDesc {
+		operator = "<"
+	}
+
+	if nullsLast {
+		return "(? " + operator + " ? OR ? IS NULL)", []interface{}{column, value, column}
+	}
+	return "? " + operator + " ?", []interface{}{column, value}
+}
@@ -0,0 +1,141 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arash-mosavi/go-base-repository/internal/fakesql"
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/postgres"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+)
+
+// named returns the rows of users from alternating IDs and names
+func named(rows ...interface{}) fakesql.Result {
+	result := fakesql.Result{Columns: []string{"id", "name"}}
+	for i := 0; i < len(rows); i += 2 {
+		result.Rows = append(result.Rows, []driver.Value{int64(rows[i].(int)), rows[i+1]})
+	}
+	return result
+}
+
+// ids returns the IDs of users in order
+func ids(users []*User) []int {
+	result := make([]int, len(users))
+	for i, user := range users {
+		result[i] = user.ID
+	}
+	return result
+}
+
+// byName is the first page of two users with three logins, ordered by name
+var byName = types.PageRequest{
+	Where:   identifier.New().Equal("logins", 3),
+	OrderBy: types.SortSpec{{Field: "name", Direction: types.SortAsc}},
+	Limit:   2,
+}
+
+func TestFindPage(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+
+	fake.Expect("SELECT", named(1, "Ann", 2, "Bob", 3, "Bob"))
+	first, err := repo.FindPage(context.Background(), byName)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ids(first.Items), []int{1, 2}) || first.NextCursor == "" || first.PrevCursor != "" {
+		t.Fatalf("Expected users 1 and 2 with only a next cursor, got %v, %+v", ids(first.Items), first)
+	}
+
+	next := byName
+	next.Cursor = first.NextCursor
+	fake.Expect("SELECT", named(3, "Bob"))
+	second, err := repo.FindPage(context.Background(), next)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ids(second.Items), []int{3}) || second.NextCursor != "" || second.PrevCursor == "" {
+		t.Fatalf("Expected user 3 with only a previous cursor, got %v, %+v", ids(second.Items), second)
+	}
+
+	previous := byName
+	previous.Cursor = second.PrevCursor
+	// rows before the cursor are fetched in reverse order
+	fake.Expect("SELECT", named(2, "Bob", 1, "Ann"))
+	back, err := repo.FindPage(context.Background(), previous)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(ids(back.Items), []int{1, 2}) || back.NextCursor == "" || back.PrevCursor != "" {
+		t.Errorf("Expected users 1 and 2 with only a next cursor, got %v, %+v", ids(back.Items), back)
+	}
+
+	statements := fake.Statements()
+	expected := []string{
+		`SELECT * FROM "users" WHERE "logins" = $1 AND "users"."deleted_at" IS NULL ORDER BY "name" ASC NULLS LAST, "id" ASC NULLS LAST LIMIT $2`,
+		// after Bob with ID 2: a later name, or Bob with a later ID
+		`SELECT * FROM "users" WHERE "logins" = $1 AND (((("name" > $2 OR "name" IS NULL)) OR ("name" = $3 AND ("id" > $4 OR "id" IS NULL)))) AND "users"."deleted_at" IS NULL ORDER BY "name" ASC NULLS LAST, "id" ASC NULLS LAST LIMIT $5`,
+		// before Bob with ID 3, in reverse order
+		`SELECT * FROM "users" WHERE "logins" = $1 AND ((("name" < $2) OR ("name" = $3 AND "id" < $4))) AND "users"."deleted_at" IS NULL ORDER BY "name" DESC NULLS FIRST, "id" DESC NULLS FIRST LIMIT $5`,
+	}
+	if len(statements) != len(expected) {
+		t.Fatalf("Expected %d statements, got %v", len(expected), statements)
+	}
+	for i, statement := range statements {
+		if statement.SQL != expected[i] {
+			t.Errorf("Expected %s, got %s", expected[i], statement.SQL)
+		}
+	}
+	if args := statements[1].Args; !reflect.DeepEqual(args[1:4], []driver.Value{"Bob", "Bob", int64(2)}) {
+		t.Errorf("Expected the position after Bob with ID 2, got %v", args)
+	}
+	if args := statements[2].Args; !reflect.DeepEqual(args[1:4], []driver.Value{"Bob", "Bob", int64(3)}) {
+		t.Errorf("Expected the position before Bob with ID 3, got %v", args)
+	}
+}
+
+func TestFindPage_RejectsForeignCursors(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+
+	fake.Expect("SELECT", named(1, "Ann", 2, "Bob", 3, "Bob"))
+	first, err := repo.FindPage(context.Background(), byName)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	payload, signature, _ := strings.Cut(first.NextCursor, ".")
+	tampered := []byte(payload)
+	tampered[len(tampered)/2] ^= 1
+
+	otherFilter := byName
+	otherFilter.Where = identifier.New().Equal("logins", 4)
+	otherSort := byName
+	otherSort.OrderBy = types.SortSpec{{Field: "name", Direction: types.SortDesc}}
+	tests := map[string]types.PageRequest{
+		"tampered":     {Where: byName.Where, OrderBy: byName.OrderBy, Cursor: string(tampered) + "." + signature},
+		"other filter": otherFilter,
+		"other sort":   otherSort,
+	}
+
+	for name, req := range tests {
+		t.Run(name, func(t *testing.T) {
+			if req.Cursor == "" {
+				req.Cursor = first.NextCursor
+			}
+			if _, err := repo.FindPage(context.Background(), req); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+				t.Errorf("Expected ErrInvalidFilter, got %v", err)
+			}
+		})
+	}
+
+	if statements := fake.SQL(); len(statements) != 1 {
+		t.Errorf("Expected no queries for rejected cursors, got %v", statements[1:])
+	}
+}
@@ -0,0 +1,194 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Patch applies changes, a types.UpdateSet or a map of columns to values, to the live row
+// matching filter and returns it as updated. Unlike Update, columns not named in changes are left
+// untouched, and operators such as Increment are computed by the database. For Versioned
+// entities, a version in changes is the version the row is expected to have rather than a new
+// value; without one, the row must still have the version it was read with.
+func (r *BaseRepository[T]) Patch(ctx context.Context, filter types.Identifier, changes map[string]interface{}) (T, error) {
+	var zero T
+
+	db := r.conn(ctx)
+
+	var version string
+	var expected int64
+	var checked bool
+	if r.versioned() {
+		fields, err := r.fields()
+		if err != nil {
+			return zero, fmt.Errorf("failed to patch: %w", err)
+		}
+		if version, err = fields.Version(); err != nil {
+			return zero, fmt.Errorf("failed to patch: %w", err)
+		}
+		if changes, expected, checked, err = fields.ExpectedVersion(changes); err != nil {
+			return zero, fmt.Errorf("failed to patch: %w", err)
+		}
+	}
+
+	assignments, err := r.assignments(changes)
+	if err != nil {
+		return zero, fmt.Errorf("failed to patch: %w", err)
+	}
+
+	current := newEntity[T]()
+	query, err := r.applyRequiredFilter(db, filter)
+	if err != nil {
+		return zero, fmt.Errorf("failed to patch: %w", err)
+	}
+	if err := query.First(current).Error; err != nil {
+		return zero, fmt.Errorf("failed to patch: %w", translateError(err))
+	}
+
+	update := db.Model(current)
+	if version != "" {
+		if !checked {
+			expected = interface{}(current).(types.Versioned).GetVersion()
+		}
+		update = update.Where(matchVersion(version, expected))
+	}
+
+	result := update.Updates(assignments)
+	if result.Error != nil {
+		return current, fmt.Errorf("failed to patch: %w", translateError(result.Error))
+	}
+	if version != "" && result.RowsAffected == 0 {
+		return current, fmt.Errorf("failed to patch: the stored version differs: %w", repoErrors.ErrConflict)
+	}
+
+	if err := db.First(current).Error; err != nil {
+		return current, fmt.Errorf("failed to reload patched entity: %w", translateError(err))
+	}
+
+	return current, nil
+}
+
+// UpdateMany applies changes to every live row matching filter in a single UPDATE and returns
+// the number of rows matched, including those the changes left as they were. A filter without
+// conditions is rejected; identifier.All() updates every live row.
+func (r *BaseRepository[T]) UpdateMany(ctx context.Context, filter types.Identifier, changes map[string]interface{}) (int64, error) {
+	conditions, err := r.manyConditions(filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update many: %w", err)
+	}
+	assignments, err := r.assignments(changes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update many: %w", err)
+	}
+
+	db := r.conn(ctx)
+	if len(conditions) == 0 {
+		db = db.Session(&gorm.Session{AllowGlobalUpdate: true})
+	}
+
+	result := where(db.Model(newEntity[T]()), conditions).Updates(assignments)
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to update many: %w", translateError(result.Error))
+	}
+
+	return result.RowsAffected, nil
+}
+
+// DeleteMany permanently removes every row matching filter in a single DELETE and returns the
+// number removed. A filter without conditions is rejected; identifier.All() removes every row.
+func (r *BaseRepository[T]) DeleteMany(ctx context.Context, filter types.Identifier) (int64, error) {
+	conditions, err := r.manyConditions(filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete many: %w", err)
+	}
+
+	db := r.conn(ctx).Unscoped()
+	if len(conditions) == 0 {
+		db = db.Session(&gorm.Session{AllowGlobalUpdate: true})
+	}
+
+	result := where(db, conditions).Delete(newEntity[T]())
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete many: %w", translateError(result.Error))
+	}
+
+	return result.RowsAffected, nil
+}
+
+// assignments validates changes against the columns of T and renders them as the column
+// assignments of an UPDATE, incrementing the version of Versioned entities, which changes must
+// not name. GORM adds the modification time.
+func (r *BaseRepository[T]) assignments(changes map[string]interface{}) (map[string]interface{}, error) {
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("%w: no changes", repoErrors.ErrInvalidUpdate)
+	}
+
+	fields, err := r.fields()
+	if err != nil {
+		return nil, err
+	}
+	resolved, err := fields.Updates(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	assignments := make(map[string]interface{}, len(resolved))
+	for name, value := range resolved {
+		change := types.AsFieldUpdate(value)
+		column := clause.Column{Name: name}
+
+		switch change.Operator {
+		case types.OpSet:
+			assignments[name] = change.Value
+		case types.OpUnset:
+			assignments[name] = nil
+		case types.OpIncrement:
+			assignments[name] = gorm.Expr("COALESCE(?, 0) + ?", column, change.Value)
+		case types.OpMultiply:
+			assignments[name] = gorm.Expr("COALESCE(?, 0) * ?", column, change.Value)
+		case types.OpMin:
+			assignments[name] = gorm.Expr("LEAST(?, ?)", column, change.Value)
+		case types.OpMax:
+			assignments[name] = gorm.Expr("GREATEST(?, ?)", column, change.Value)
+		case types.OpPush:
+			assignments[name] = arrayExpression("array_append", column, change.Value)
+		case types.OpPull:
+			assignments[name] = arrayExpression("array_remove", column, change.Value)
+		default:
+			return nil, fmt.Errorf("%w: unknown operator %q on column %q", repoErrors.ErrInvalidUpdate, change.Operator, name)
+		}
+	}
+
+	if r.versioned() {
+		version, err := fields.Version()
+		if err != nil {
+			return nil, err
+		}
+		if _, changed := assignments[version]; changed {
+			return nil, fmt.Errorf("%w: column %q is incremented on every update, compare it in the filter instead", repoErrors.ErrInvalidUpdate, version)
+		}
+		assignments[version] = gorm.Expr("COALESCE(?, 0) + 1", clause.Column{Name: version})
+	}
+
+	return assignments, nil
+}
+
+// arrayExpression applies the array function to column once per value, so each value takes the
+// element type of the column:
+//
+//	array_append(array_append(tags, ?), ?)
+func arrayExpression(function string, column clause.Column, values interface{}) clause.Expr {
+	list, _ := values.([]interface{})
+	sql := strings.Repeat(function+"(", len(list)) + "?" + strings.Repeat(", ?)", len(list))
+
+	vars := make([]interface{}, 0, len(list)+1)
+	vars = append(vars, column)
+	vars = append(vars, list...)
+	return gorm.Expr(sql, vars...)
+}
@@ -0,0 +1,172 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arash-mosavi/go-base-repository/internal/fakesql"
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/postgres"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+)
+
+func TestPatch(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+	fake.Expect("SELECT", users(1))
+	fake.Expect("UPDATE", fakesql.Result{RowsAffected: 1})
+	fake.Expect("SELECT", users(1))
+
+	changes := types.NewUpdateSet().Increment("logins", 1).Push("tags", "a", "b").Unset("slug")
+	user, err := repo.Patch(context.Background(), identifier.New().Equal("name", "John"), changes)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user.ID != 1 {
+		t.Errorf("Expected the patched row, got %v", user)
+	}
+
+	sql := fake.SQL()
+	if len(sql) != 3 {
+		t.Fatalf("Expected 3 statements, got %v", sql)
+	}
+	update := sql[1]
+	for _, assignment := range []string{
+		`"logins"=COALESCE("logins", 0) + $`,
+		`"tags"=array_append(array_append("tags", $`,
+		`"slug"=$2`,
+		`"updated_at"=$`,
+	} {
+		if !strings.Contains(update, assignment) {
+			t.Errorf("Expected %s, got %s", assignment, update)
+		}
+	}
+	if args := fake.Statements()[1].Args; args[1] != nil {
+		t.Errorf("Expected slug to be cleared, got %v", args)
+	}
+	if strings.Contains(update, `"name"=`) || strings.Contains(update, `"email"=`) {
+		t.Errorf("Expected columns not named in changes to be left alone, got %s", update)
+	}
+	if !strings.Contains(update, `"users"."deleted_at" IS NULL AND "id" = $`) {
+		t.Errorf("Expected the update to target the matched row, got %s", update)
+	}
+}
+
+func TestPatch_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes types.UpdateSet
+	}{
+		{"no changes", types.NewUpdateSet()},
+		{"unknown field", types.NewUpdateSet().Set("missing", 1)},
+		{"increment text", types.NewUpdateSet().Increment("name", 1)},
+		{"push to scalar", types.NewUpdateSet().Push("logins", 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := fakesql.Open(t)
+			repo := postgres.NewBaseRepository[*User](db)
+
+			_, err := repo.Patch(context.Background(), identifier.New().Equal("name", "John"), tt.changes)
+			if !errors.Is(err, repoErrors.ErrInvalidUpdate) {
+				t.Errorf("Expected ErrInvalidUpdate, got %v", err)
+			}
+			if sql := fake.SQL(); len(sql) != 0 {
+				t.Errorf("Expected no statements, got %v", sql)
+			}
+		})
+	}
+}
+
+func TestUpdateMany(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+	fake.Expect("UPDATE", fakesql.Result{RowsAffected: 3})
+
+	count, err := repo.UpdateMany(context.Background(), identifier.New().GreaterThan("logins", 10), types.NewUpdateSet().Set("name", "Frequent"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 rows, got %d", count)
+	}
+
+	sql := fake.SQL()
+	if len(sql) != 1 {
+		t.Fatalf("Expected a single statement, got %v", sql)
+	}
+	expected := `UPDATE "users" SET "name"=$1,"updated_at"=$2 WHERE "logins" > $3 AND "users"."deleted_at" IS NULL`
+	if sql[0] != expected {
+		t.Errorf("Expected %s, got %s", expected, sql[0])
+	}
+}
+
+func TestDeleteMany(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   types.Identifier
+		expected string
+	}{
+		{"filtered", identifier.New().GreaterThan("logins", 10), `DELETE FROM "users" WHERE "logins" > $1`},
+		{"everything", identifier.All(), `DELETE FROM "users"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, fake := fakesql.Open(t)
+			repo := postgres.NewBaseRepository[*User](db)
+			fake.Expect("DELETE", fakesql.Result{RowsAffected: 4})
+
+			count, err := repo.DeleteMany(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if count != 4 {
+				t.Errorf("Expected 4 rows, got %d", count)
+			}
+			if sql := fake.SQL(); len(sql) != 1 || sql[0] != tt.expected {
+				t.Errorf("Expected %s, got %v", tt.expected, sql)
+			}
+		})
+	}
+}
+
+func TestUpdateMany_All(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+	fake.Expect("UPDATE", fakesql.Result{RowsAffected: 7})
+
+	count, err := repo.UpdateMany(context.Background(), identifier.All(), types.NewUpdateSet().Set("name", "Everyone"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if count != 7 {
+		t.Errorf("Expected 7 rows, got %d", count)
+	}
+
+	expected := `UPDATE "users" SET "name"=$1,"updated_at"=$2 WHERE "users"."deleted_at" IS NULL`
+	if sql := fake.SQL(); len(sql) != 1 || sql[0] != expected {
+		t.Errorf("Expected %s, got %v", expected, sql)
+	}
+}
+
+func TestWriteMany_RequireFilter(t *testing.T) {
+	for _, filter := range []types.Identifier{nil, identifier.New()} {
+		db, fake := fakesql.Open(t)
+		repo := postgres.NewBaseRepository[*User](db)
+
+		if _, err := repo.UpdateMany(context.Background(), filter, types.NewUpdateSet().Set("name", "Everyone")); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+			t.Errorf("Expected ErrInvalidFilter from UpdateMany, got %v", err)
+		}
+		if _, err := repo.DeleteMany(context.Background(), filter); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+			t.Errorf("Expected ErrInvalidFilter from DeleteMany, got %v", err)
+		}
+		if sql := fake.SQL(); len(sql) != 0 {
+			t.Errorf("Expected no statements, got %v", sql)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"iter"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Stream iterates over the entities matching the identifier in primary key order, fetching them
+// in batches of the configured batch size instead of loading them all at once. Each batch is a
+// separate query continuing after the last key of the previous one, read through GORM's Rows
+// and closed before its entities are yielded, so no connection or transaction is held while the
+// consumer runs and stopping early leaves nothing open. Rows written while the iteration runs
+// may or may not be seen. A failure, including the cancellation of ctx, is yielded once as the
+// last element.
+func (r *BaseRepository[T]) Stream(ctx context.Context, filter types.Identifier) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+
+		var after interface{}
+		for {
+			query, err := r.batchQuery(r.conn(ctx), filter, after)
+			if err != nil {
+				yield(zero, fmt.Errorf("failed to stream: %w", err))
+				return
+			}
+
+			batch, err := r.fetchBatch(query)
+			if err != nil {
+				yield(zero, fmt.Errorf("failed to stream: %w", translateError(err)))
+				return
+			}
+
+			for _, entity := range batch {
+				if !yield(entity, nil) {
+					return
+				}
+			}
+
+			if len(batch) < r.settings.batchSize {
+				return
+			}
+			after = batch[len(batch)-1].GetID()
+		}
+	}
+}
+
+// batchQuery selects the next batch of the rows of db matching filter: those whose primary key
+// follows after, or the first ones when after is nil
+func (r *BaseRepository[T]) batchQuery(db *gorm.DB, filter types.Identifier, after interface{}) (*gorm.DB, error) {
+	s, err := r.schema()
+	if err != nil {
+		return nil, err
+	}
+	column := clause.Column{Table: clause.CurrentTable, Name: primaryKey(s)}
+
+	query, err := r.applyFilter(db.Model(newEntity[T]()), filter)
+	if err != nil {
+		return nil, err
+	}
+	if after != nil {
+		query = query.Where(clause.Gt{Column: column, Value: after})
+	}
+
+	return query.Order(clause.OrderByColumn{Column: column}).Limit(r.settings.batchSize), nil
+}
+
+// fetchBatch runs query through Rows and scans the returned rows. The batch is read completely
+// and the rows closed before it is yielded, so the consumer may use the connection in between.
+func (r *BaseRepository[T]) fetchBatch(query *gorm.DB) ([]T, error) {
+	rows, err := query.Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	batch := make([]T, 0, r.settings.batchSize)
+	for rows.Next() {
+		entity := newEntity[T]()
+		if err := query.ScanRows(rows, entity); err != nil {
+			return nil, err
+		}
+		batch = append(batch, entity)
+	}
+
+	return batch, rows.Err()
+}
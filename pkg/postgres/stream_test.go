@@ -0,0 +1,98 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arash-mosavi/go-base-repository/internal/fakesql"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/postgres"
+)
+
+// users returns the rows of the users with ids
+func users(ids ...int64) fakesql.Result {
+	result := fakesql.Result{Columns: []string{"id", "name"}}
+	for _, id := range ids {
+		result.Rows = append(result.Rows, []driver.Value{id, "John"})
+	}
+	return result
+}
+
+func TestStream_Batches(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db, postgres.WithBatchSize(2))
+	fake.Expect("SELECT", users(1, 2))
+	fake.Expect("SELECT", users(3))
+
+	var ids []int
+	for user, err := range repo.Stream(context.Background(), identifier.New().Equal("name", "John")) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		ids = append(ids, user.ID)
+	}
+
+	if expected := []int{1, 2, 3}; !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Expected %v, got %v", expected, ids)
+	}
+
+	statements := fake.Statements()
+	if len(statements) != 2 {
+		t.Fatalf("Expected 2 statements, got %v", fake.SQL())
+	}
+	if strings.Contains(statements[0].SQL, `"users"."id" >`) {
+		t.Errorf("Expected the first batch to start at the first row, got %s", statements[0].SQL)
+	}
+	next := statements[1]
+	if !strings.Contains(next.SQL, `"users"."id" >`) || !strings.Contains(next.SQL, `ORDER BY "users"."id" LIMIT`) {
+		t.Errorf("Expected the second batch to continue after the last key, got %s", next.SQL)
+	}
+	if expected := []driver.Value{"John", 2, 2}; !reflect.DeepEqual(next.Args, expected) {
+		t.Errorf("Expected %v, got %v", expected, next.Args)
+	}
+	for _, sql := range fake.SQL() {
+		if strings.HasPrefix(sql, "BEGIN") || strings.HasPrefix(sql, "DECLARE") {
+			t.Errorf("Expected no transaction or cursor, got %s", sql)
+		}
+	}
+}
+
+func TestStream_StopEarly(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db, postgres.WithBatchSize(2))
+	fake.Expect("SELECT", users(1, 2))
+
+	for _, err := range repo.Stream(context.Background(), identifier.New()) {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		break
+	}
+
+	if got := len(fake.Statements()); got != 1 {
+		t.Errorf("Expected 1 statement, got %v", fake.SQL())
+	}
+	if open := fake.OpenRows(); open != 0 {
+		t.Errorf("Expected the rows to be closed, got %d open", open)
+	}
+}
+
+func TestStream_Error(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+	failure := errors.New("failure")
+	fake.Expect("SELECT", fakesql.Result{Err: failure})
+
+	var errs []error
+	for _, err := range repo.Stream(context.Background(), identifier.New()) {
+		errs = append(errs, err)
+	}
+
+	if len(errs) != 1 || !errors.Is(errs[0], failure) {
+		t.Errorf("Expected the failure once, got %v", errs)
+	}
+}
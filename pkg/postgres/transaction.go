@@ -0,0 +1,178 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/interfaces"
+	"github.com/arash-mosavi/go-base-repository/pkg/retry"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// Errors of the deprecated BeginTransaction, CommitTransaction and RollbackTransaction
+var (
+	errTransactionBegun = errors.New("a transaction is already in progress on this repository")
+	errNoTransaction    = errors.New("no transaction in progress on this repository")
+)
+
+// SQLSTATE codes of transaction failures that are safe to retry
+const (
+	serializationFailureCode = "40001"
+	deadlockDetectedCode     = "40P01"
+)
+
+// txKey is the context key under which the active transaction is stored
+type txKey struct{}
+
+// ContextWithTx returns a copy of ctx carrying tx. Repository methods called with the
+// returned context run on tx instead of their own connection.
+func ContextWithTx(ctx context.Context, tx *gorm.DB) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// txFromContext returns the transaction bound to ctx, if any
+func txFromContext(ctx context.Context) (*gorm.DB, bool) {
+	tx, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return tx, ok && tx != nil
+}
+
+// manualTx is the transaction begun by the deprecated BeginTransaction
+type manualTx struct {
+	mu sync.Mutex
+	tx *gorm.DB
+}
+
+// current returns the transaction in progress, if any
+func (m *manualTx) current() (*gorm.DB, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tx, m.tx != nil
+}
+
+// transaction returns the transaction calls with ctx take part in: the one bound to ctx, or else
+// the one begun by BeginTransaction
+func (r *BaseRepository[T]) transaction(ctx context.Context) (*gorm.DB, bool) {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx, true
+	}
+	return r.manual.current()
+}
+
+// WithTransaction runs fn inside a database transaction. The transaction is bound to the
+// context handed to fn, so every repository method called with that context takes part
+// in it. The transaction is committed when fn returns nil and rolled back when it returns
+// an error or panics.
+//
+// A transaction that fails with a serialization failure (40001) or a deadlock (40P01) is
+// run again as configured by WithRetryPolicy, so fn may be called more than once and must
+// be safe to repeat.
+//
+// If ctx already carries a transaction, or one was begun with BeginTransaction, fn runs inside
+// a SAVEPOINT of it instead: an error or panic in fn rolls back to the savepoint only, leaving
+// the outer transaction usable, and the outer transaction decides whether the work is finally
+// committed. Nested calls are never retried on their own since the outer transaction is
+// already lost.
+//
+// opts sets the isolation level, read-only mode and maximum duration of the transaction.
+// A savepoint keeps the isolation level and access mode of its outer transaction.
+func (r *BaseRepository[T]) WithTransaction(ctx context.Context, fn func(ctx context.Context, repo interfaces.PostgresBaseRepository[T]) error, opts ...*types.TxOptions) error {
+	txOptions := types.TxOptionsFrom(opts)
+	if txOptions.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, txOptions.MaxDuration)
+		defer cancel()
+	}
+
+	run := func(db *gorm.DB) error {
+		return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(ContextWithTx(ctx, tx), r)
+		}, txOptions.SQLOptions())
+	}
+
+	// GORM opens a savepoint instead of a new transaction when db is already a transaction,
+	// unless the connection was configured with DisableNestedTransaction
+	if tx, ok := r.transaction(ctx); ok {
+		return translateError(run(tx))
+	}
+
+	err := retry.Do(ctx, r.settings.retry, isRetryable, func() error {
+		return run(r.db)
+	})
+	return translateError(err)
+}
+
+// isRetryable reports whether err is a PostgreSQL error after which the transaction can be run again
+func isRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == serializationFailureCode || pgErr.Code == deadlockDetectedCode
+}
+
+// BeginTransaction begins a transaction that every later call on this repository takes part in,
+// from any goroutine, until CommitTransaction or RollbackTransaction ends it.
+//
+// Deprecated: the transaction belongs to the repository rather than to a context, so it cannot
+// be shared with other repositories and leaks into concurrent callers. Use WithTransaction.
+func (r *BaseRepository[T]) BeginTransaction(ctx context.Context) error {
+	r.manual.mu.Lock()
+	defer r.manual.mu.Unlock()
+
+	if r.manual.tx != nil {
+		return errTransactionBegun
+	}
+
+	// the transaction outlives the call, so it must not be rolled back when ctx is cancelled
+	tx := r.db.WithContext(context.WithoutCancel(ctx)).Begin()
+	if tx.Error != nil {
+		return fmt.Errorf("failed to begin transaction: %w", translateError(tx.Error))
+	}
+	r.manual.tx = tx
+	return nil
+}
+
+// CommitTransaction commits the transaction begun by BeginTransaction.
+//
+// Deprecated: use WithTransaction.
+func (r *BaseRepository[T]) CommitTransaction(ctx context.Context) error {
+	tx, err := r.manual.end()
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", translateError(err))
+	}
+	return nil
+}
+
+// RollbackTransaction rolls back the transaction begun by BeginTransaction.
+//
+// Deprecated: use WithTransaction.
+func (r *BaseRepository[T]) RollbackTransaction(ctx context.Context) error {
+	tx, err := r.manual.end()
+	if err != nil {
+		return err
+	}
+	if err := tx.Rollback().Error; err != nil {
+		return fmt.Errorf("failed to rollback transaction: %w", translateError(err))
+	}
+	return nil
+}
+
+// end detaches the transaction in progress so it can be committed or rolled back
+func (m *manualTx) end() (*gorm.DB, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tx := m.tx
+	if tx == nil {
+		return nil, errNoTransaction
+	}
+	m.tx = nil
+	return tx, nil
+}
@@ -0,0 +1,193 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arash-mosavi/go-base-repository/internal/fakesql"
+	"github.com/arash-mosavi/go-base-repository/pkg/interfaces"
+	"github.com/arash-mosavi/go-base-repository/pkg/postgres"
+	"github.com/arash-mosavi/go-base-repository/pkg/retry"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// verbs returns the leading keywords of the statements sent to db
+func verbs(db *fakesql.DB) []string {
+	var verbs []string
+	for _, sql := range db.SQL() {
+		switch {
+		case strings.HasPrefix(sql, "SAVEPOINT"), strings.HasPrefix(sql, "ROLLBACK TO SAVEPOINT"):
+			verbs = append(verbs, strings.TrimSuffix(sql, sql[strings.LastIndex(sql, " "):]))
+		default:
+			verb, _, _ := strings.Cut(sql, " ")
+			verbs = append(verbs, verb)
+		}
+	}
+	return verbs
+}
+
+func TestWithTransaction_Commit(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+
+	err := repo.WithTransaction(context.Background(), func(ctx context.Context, repo interfaces.PostgresBaseRepository[*User]) error {
+		_, err := repo.Insert(ctx, &User{Name: "John"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"BEGIN", "INSERT", "COMMIT"}
+	if got := verbs(fake); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestWithTransaction_RollbackOnError(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+
+	failure := errors.New("failure")
+	err := repo.WithTransaction(context.Background(), func(ctx context.Context, repo interfaces.PostgresBaseRepository[*User]) error {
+		if _, err := repo.Insert(ctx, &User{Name: "John"}); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Expected the closure's error, got %v", err)
+	}
+
+	expected := []string{"BEGIN", "INSERT", "ROLLBACK"}
+	if got := verbs(fake); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestWithTransaction_RetriesSerializationFailures(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db, postgres.WithRetryPolicy(retry.Policy{MaxAttempts: 3}))
+	fake.Expect("INSERT", fakesql.Result{Err: &pgconn.PgError{Code: "40001"}})
+
+	calls := 0
+	err := repo.WithTransaction(context.Background(), func(ctx context.Context, repo interfaces.PostgresBaseRepository[*User]) error {
+		calls++
+		_, err := repo.Insert(ctx, &User{Name: "John"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected the closure to run twice, ran %d times", calls)
+	}
+
+	expected := []string{"BEGIN", "INSERT", "ROLLBACK", "BEGIN", "INSERT", "COMMIT"}
+	if got := verbs(fake); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestWithTransaction_DoesNotRetryOtherErrors(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db, postgres.WithRetryPolicy(retry.Policy{MaxAttempts: 3}))
+	fake.Expect("INSERT", fakesql.Result{Err: &pgconn.PgError{Code: "23505"}})
+
+	calls := 0
+	err := repo.WithTransaction(context.Background(), func(ctx context.Context, repo interfaces.PostgresBaseRepository[*User]) error {
+		calls++
+		_, err := repo.Insert(ctx, &User{Name: "John"})
+		return err
+	})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if calls != 1 {
+		t.Errorf("Expected the closure to run once, ran %d times", calls)
+	}
+}
+
+func TestManualTransaction(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+	ctx := context.Background()
+
+	if err := repo.BeginTransaction(ctx); err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	if err := repo.BeginTransaction(ctx); err == nil {
+		t.Error("Expected an error beginning a second transaction")
+	}
+	if _, err := repo.Insert(ctx, &User{Name: "John"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := repo.CommitTransaction(ctx); err != nil {
+		t.Fatalf("Failed to commit transaction: %v", err)
+	}
+	if err := repo.RollbackTransaction(ctx); err == nil {
+		t.Error("Expected an error without a transaction in progress")
+	}
+	if _, err := repo.Insert(ctx, &User{Name: "Jane"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"BEGIN", "INSERT", "COMMIT", "INSERT"}
+	if got := verbs(fake); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestWithTransaction_NestedRollsBackToSavepoint(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+
+	failure := errors.New("failure")
+	err := repo.WithTransaction(context.Background(), func(ctx context.Context, repo interfaces.PostgresBaseRepository[*User]) error {
+		if _, err := repo.Insert(ctx, &User{Name: "John"}); err != nil {
+			return err
+		}
+		err := repo.WithTransaction(ctx, func(ctx context.Context, repo interfaces.PostgresBaseRepository[*User]) error {
+			if _, err := repo.Insert(ctx, &User{Name: "Jane"}); err != nil {
+				return err
+			}
+			return failure
+		})
+		if !errors.Is(err, failure) {
+			t.Errorf("Expected the inner closure's error, got %v", err)
+		}
+		_, err = repo.Insert(ctx, &User{Name: "Jack"})
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"BEGIN", "INSERT", "SAVEPOINT", "INSERT", "ROLLBACK TO SAVEPOINT", "INSERT", "COMMIT"}
+	if got := verbs(fake); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestWithTransaction_NestedCommitsWithOuter(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+
+	err := repo.WithTransaction(context.Background(), func(ctx context.Context, repo interfaces.PostgresBaseRepository[*User]) error {
+		return repo.WithTransaction(ctx, func(ctx context.Context, repo interfaces.PostgresBaseRepository[*User]) error {
+			_, err := repo.Insert(ctx, &User{Name: "Jane"})
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := []string{"BEGIN", "SAVEPOINT", "INSERT", "COMMIT"}
+	if got := verbs(fake); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	repoSchema "github.com/arash-mosavi/go-base-repository/pkg/schema"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Upsert inserts entity, or overwrites the row it conflicts with, in a single
+// INSERT ... ON CONFLICT (...) DO UPDATE statement. The conflict columns default to the fields of
+// the filter's equality conditions; an existing row is only overwritten when it is live and also
+// matches the filter, otherwise ErrConflict is returned. The stored row is returned.
+func (r *BaseRepository[T]) Upsert(ctx context.Context, filter types.Identifier, entity T, opts types.UpsertOptions) (T, error) {
+	var zero T
+
+	entitySchema, err := r.schema()
+	if err != nil {
+		return zero, fmt.Errorf("failed to upsert: %w", err)
+	}
+	fields := repoSchema.FromGORM(entitySchema)
+
+	conditions, err := identifier.Conditions(filter)
+	if err != nil {
+		return zero, fmt.Errorf("failed to upsert: %w", err)
+	}
+	conditions, err = fields.Conditions(conditions)
+	if err != nil {
+		return zero, fmt.Errorf("failed to upsert: %w", err)
+	}
+
+	conflict, err := fields.Fields(opts.ConflictFields)
+	if err != nil {
+		return zero, fmt.Errorf("failed to upsert: %w", err)
+	}
+	if len(conflict) == 0 {
+		conflict = equalityFields(conditions)
+	}
+	if len(conflict) == 0 {
+		conflict = []string{primaryKey(entitySchema)}
+	}
+
+	updates, err := fields.Fields(opts.UpdateFields)
+	if err != nil {
+		return zero, fmt.Errorf("failed to upsert: %w", err)
+	}
+	updates = updateColumns(entitySchema, updates, conflict)
+
+	onConflict := clause.OnConflict{DoUpdates: clause.AssignmentColumns(updates)}
+	for _, column := range conflict {
+		onConflict.Columns = append(onConflict.Columns, clause.Column{Name: column})
+	}
+	// unqualified columns would be ambiguous with the EXCLUDED row
+	onConflict.Where = clause.Where{Exprs: identifier.Expressions(qualify(conditions, entitySchema.Table))}
+	if entitySchema.LookUpField(deletedAtColumn) != nil {
+		onConflict.Where.Exprs = append(onConflict.Where.Exprs, clause.Expr{
+			SQL:  "? IS NULL",
+			Vars: []interface{}{clause.Column{Table: entitySchema.Table, Name: deletedAtColumn}},
+		})
+	}
+
+	db := r.conn(ctx)
+	result := db.Clauses(onConflict).Create(entity)
+	if result.Error != nil {
+		return zero, fmt.Errorf("failed to upsert: %w", translateError(result.Error))
+	}
+	if result.RowsAffected == 0 {
+		return zero, fmt.Errorf("failed to upsert: the conflicting row is soft-deleted or does not match the filter: %w", repoErrors.ErrConflict)
+	}
+
+	stored := newEntity[T]()
+	if err := db.First(stored, entity.GetID()).Error; err != nil {
+		return entity, fmt.Errorf("failed to reload upserted entity: %w", translateError(err))
+	}
+
+	return stored, nil
+}
+
+// equalityFields returns the fields compared for equality by the top-level conditions
+func equalityFields(conditions []identifier.Condition) []string {
+	var fields []string
+	for _, c := range conditions {
+		if c.Operator == identifier.OpEqual && c.Value != nil {
+			fields = append(fields, c.Field)
+		}
+	}
+	return fields
+}
+
+// updateColumns returns the columns overwritten on conflict: the requested ones plus the
+// modification time, or every column but the primary key, the creation time and the conflict
+// columns when none are requested. The soft delete column is never overwritten, so an upsert
+// does not restore a soft-deleted row.
+func updateColumns(s *schema.Schema, requested, conflict []string) []string {
+	skip := make(map[string]bool, len(requested)+len(conflict)+1)
+	skip[deletedAtColumn] = true
+	columns := make([]string, 0, len(s.DBNames))
+	for _, column := range requested {
+		if !skip[column] {
+			skip[column] = true
+			columns = append(columns, column)
+		}
+	}
+
+	for _, column := range conflict {
+		skip[column] = true
+	}
+	for _, field := range s.Fields {
+		if field.DBName == "" || skip[field.DBName] || field.PrimaryKey || field.AutoCreateTime > 0 {
+			continue
+		}
+		if len(requested) == 0 || field.AutoUpdateTime > 0 {
+			skip[field.DBName] = true
+			columns = append(columns, field.DBName)
+		}
+	}
+
+	return columns
+}
+
+// qualify prefixes the fields of conditions, including those inside groups, with table
+func qualify(conditions []identifier.Condition, table string) []identifier.Condition {
+	qualified := make([]identifier.Condition, len(conditions))
+	for i, c := range conditions {
+		if c.IsGroup() {
+			operands := make([][]identifier.Condition, len(c.Operands))
+			for j, operand := range c.Operands {
+				operands[j] = qualify(operand, table)
+			}
+			c.Operands = operands
+		} else {
+			c.Field = table + "." + c.Field
+		}
+		qualified[i] = c
+	}
+	return qualified
+}
@@ -0,0 +1,93 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/arash-mosavi/go-base-repository/internal/fakesql"
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/postgres"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+)
+
+func TestUpsert(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+	fake.Expect("INSERT", users(1))
+	fake.Expect("SELECT", users(1))
+
+	user, err := repo.Upsert(context.Background(), identifier.New().Equal("email", "john@example.com"), &User{Name: "John", Email: "john@example.com"}, types.UpsertOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if user.ID != 1 {
+		t.Errorf("Expected the stored row, got %v", user)
+	}
+
+	insert := fake.SQL()[0]
+	if !strings.Contains(insert, `ON CONFLICT ("email") DO UPDATE SET`) {
+		t.Errorf("Expected a conflict on email, got %s", insert)
+	}
+	if !strings.Contains(insert, `WHERE "users"."email" = `) {
+		t.Errorf("Expected the conflicting row to be matched against the filter, got %s", insert)
+	}
+	for _, column := range []string{`"name"="excluded"."name"`, `"updated_at"="excluded"."updated_at"`} {
+		if !strings.Contains(insert, column) {
+			t.Errorf("Expected %s to be overwritten, got %s", column, insert)
+		}
+	}
+	for _, column := range []string{`"id"="excluded"`, `"email"="excluded"`, `"created_at"="excluded"`, `"deleted_at"="excluded"`} {
+		if strings.Contains(insert, column) {
+			t.Errorf("Expected %s to be kept, got %s", column, insert)
+		}
+	}
+}
+
+func TestUpsert_UpdateFields(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+	fake.Expect("INSERT", users(1))
+	fake.Expect("SELECT", users(1))
+
+	_, err := repo.Upsert(context.Background(), identifier.New().Equal("email", "john@example.com"), &User{Name: "John", Email: "john@example.com"}, types.UpsertOptions{
+		ConflictFields: []string{"slug"},
+		UpdateFields:   []string{"name"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	insert := fake.SQL()[0]
+	if expected := `ON CONFLICT ("slug") DO UPDATE SET "name"="excluded"."name","updated_at"="excluded"."updated_at" WHERE`; !strings.Contains(insert, expected) {
+		t.Errorf("Expected %s, got %s", expected, insert)
+	}
+}
+
+func TestUpsert_ConflictOutsideFilter(t *testing.T) {
+	db, _ := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+
+	_, err := repo.Upsert(context.Background(), identifier.New().Equal("email", "john@example.com"), &User{Name: "John", Email: "john@example.com"}, types.UpsertOptions{})
+	if !errors.Is(err, repoErrors.ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+}
+
+func TestUpsert_SoftDeleted(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*User](db)
+
+	_, err := repo.Upsert(context.Background(), identifier.New().Equal("email", "john@example.com"), &User{Name: "John", Email: "john@example.com"}, types.UpsertOptions{})
+	if !errors.Is(err, repoErrors.ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+
+	if statements := fake.SQL(); len(statements) != 1 {
+		t.Errorf("Expected the soft-deleted row not to be read back, got %v", statements)
+	} else if !strings.Contains(statements[0], `"users"."deleted_at" IS NULL`) {
+		t.Errorf("Expected a soft-deleted conflicting row to be left alone, got %s", statements[0])
+	}
+}
@@ -0,0 +1,28 @@
+package postgres
+
+import (
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"gorm.io/gorm/clause"
+)
+
+// versioned reports whether T opts in to optimistic locking
+func (r *BaseRepository[T]) versioned() bool {
+	var entity T
+	_, ok := interface{}(entity).(types.Versioned)
+	return ok
+}
+
+// versionColumn returns the column holding the version of T
+func (r *BaseRepository[T]) versionColumn() (string, error) {
+	fields, err := r.fields()
+	if err != nil {
+		return "", err
+	}
+	return fields.Version()
+}
+
+// matchVersion returns the condition matching rows stored with version. Rows written before the
+// entity was versioned have no version, which counts as zero.
+func matchVersion(column string, version int64) clause.Expression {
+	return clause.Expr{SQL: "COALESCE(?, 0) = ?", Vars: []interface{}{clause.Column{Name: column}, version}}
+}
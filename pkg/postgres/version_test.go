@@ -0,0 +1,152 @@
+package postgres_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/arash-mosavi/go-base-repository/internal/fakesql"
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/postgres"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+)
+
+// account returns the row of the account with id stored at version
+func account(id, version int64) fakesql.Result {
+	return fakesql.Result{Columns: []string{"id", "name", "version"}, Rows: [][]driver.Value{{id, "John", version}}}
+}
+
+func TestSoftDelete_ExpectedVersion(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*Account](db)
+	fake.Expect("SELECT", account(1, 3))
+	fake.Expect("UPDATE", fakesql.Result{RowsAffected: 1})
+
+	deleted, err := repo.SoftDelete(context.Background(), identifier.New().Equal("id", 1).Equal("version", 3))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deleted.Version != 4 {
+		t.Errorf("Expected version 4, got %d", deleted.Version)
+	}
+
+	statements := fake.Statements()
+	if len(statements) != 2 {
+		t.Fatalf("Expected 2 statements, got %v", fake.SQL())
+	}
+	if strings.Contains(statements[0].SQL, "version") {
+		t.Errorf("Expected the row to be read regardless of its version, got %s", statements[0].SQL)
+	}
+	update := statements[1]
+	if !strings.Contains(update.SQL, `COALESCE("version", 0) = $`) || !strings.Contains(update.SQL, `"deleted_at"=$`) {
+		t.Errorf("Expected a versioned soft delete, got %s", update.SQL)
+	}
+	if !containsValue(update.Args, int64(3)) || !containsValue(update.Args, int64(4)) {
+		t.Errorf("Expected version 3 to be replaced by 4, got %v", update.Args)
+	}
+}
+
+func TestSoftDelete_ReadVersion(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*Account](db)
+	fake.Expect("SELECT", account(1, 2))
+	fake.Expect("UPDATE", fakesql.Result{RowsAffected: 1})
+
+	deleted, err := repo.SoftDelete(context.Background(), identifier.New().Equal("id", 1))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if deleted.Version != 3 {
+		t.Errorf("Expected version 3, got %d", deleted.Version)
+	}
+	if args := fake.Statements()[1].Args; !containsValue(args, int64(2)) {
+		t.Errorf("Expected the version read to be checked, got %v", args)
+	}
+}
+
+func TestSoftDelete_Conflict(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*Account](db)
+	fake.Expect("SELECT", account(1, 5))
+
+	_, err := repo.SoftDelete(context.Background(), identifier.New().Equal("id", 1).Equal("version", 3))
+	if !errors.Is(err, repoErrors.ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+}
+
+func TestUpdate_Conflict(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*Account](db)
+	fake.Expect("SELECT", account(1, 5))
+
+	entity := &Account{ID: 1, Name: "Jane", Version: 3}
+	_, err := repo.Update(context.Background(), identifier.New().Equal("id", 1), entity)
+	if !errors.Is(err, repoErrors.ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+	if entity.Version != 3 {
+		t.Errorf("Expected the version to be restored, got %d", entity.Version)
+	}
+	if update := fake.SQL()[1]; !strings.Contains(update, `COALESCE("version", 0) = $`) {
+		t.Errorf("Expected a versioned update, got %s", update)
+	}
+}
+
+func TestPatch_ExpectedVersion(t *testing.T) {
+	db, fake := fakesql.Open(t)
+	repo := postgres.NewBaseRepository[*Account](db)
+	fake.Expect("SELECT", account(1, 5))
+
+	_, err := repo.Patch(context.Background(), identifier.New().Equal("id", 1), types.NewUpdateSet().Set("name", "Jane").Set("version", 3))
+	if !errors.Is(err, repoErrors.ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+
+	update := fake.Statements()[1]
+	if !strings.Contains(update.SQL, `"version"=COALESCE("version", 0) + 1`) || !strings.Contains(update.SQL, `COALESCE("version", 0) = $`) {
+		t.Errorf("Expected a versioned update, got %s", update.SQL)
+	}
+	if !containsValue(update.Args, int64(3)) {
+		t.Errorf("Expected version 3 to be checked, got %v", update.Args)
+	}
+}
+
+// containsValue reports whether args contains value
+func containsValue(args []driver.Value, value driver.Value) bool {
+	for _, arg := range args {
+		if reflect.DeepEqual(arg, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestModify_RejectsVersion(t *testing.T) {
+	changes := map[string]types.UpdateSet{
+		"set":       types.NewUpdateSet().Set("name", "Jane").Set("version", 1),
+		"increment": types.NewUpdateSet().Increment("version", 10),
+	}
+
+	for name, change := range changes {
+		t.Run(name, func(t *testing.T) {
+			db, fake := fakesql.Open(t)
+			repo := postgres.NewBaseRepository[*Account](db)
+			filter := identifier.New().Equal("name", "John")
+
+			if _, err := repo.UpdateMany(context.Background(), filter, change); !errors.Is(err, repoErrors.ErrInvalidUpdate) {
+				t.Errorf("Expected UpdateMany to fail with ErrInvalidUpdate, got %v", err)
+			}
+			if _, err := repo.FindOneAndUpdate(context.Background(), filter, change, types.FindAndModifyOptions{}); !errors.Is(err, repoErrors.ErrInvalidUpdate) {
+				t.Errorf("Expected FindOneAndUpdate to fail with ErrInvalidUpdate, got %v", err)
+			}
+			if statements := fake.SQL(); len(statements) != 0 {
+				t.Errorf("Expected no statements, got %v", statements)
+			}
+		})
+	}
+}
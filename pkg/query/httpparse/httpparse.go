@@ -0,0 +1,400 @@
+// Package httpparse turns URL query strings into identifiers, sort specifications and
+// pagination parameters. Only fields listed in a Config can be filtered or sorted on, and
+// values are converted to the field's type before they reach a repository.
+//
+// The grammar is:
+//
+//	field=value              equal
+//	field[op]=value          op is one of eq, ne, gt, gte, lt, lte, like, ilike, prefix,
+//	                         suffix, exists (true/false) and null (true/false)
+//	field[in]=a,b,c          in, nin: comma-separated list
+//	field[between]=a,b       inclusive range
+//	field[like]=a%b          like, ilike: SQL LIKE pattern with at most MaxWildcards % wildcards;
+//	                         prefix and suffix take their value literally
+//	sort=-created_at,name    comma-separated, "-" for descending
+//	limit=20&offset=40       offset pagination
+//	cursor=...               keyset pagination token, exclusive with offset
+//
+// Every parameter may appear once. Unknown parameters, fields and operators are rejected with
+// ErrInvalidFilter naming the parameter.
+package httpparse
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Type is the type query-string values of a field are converted to
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Float
+	Bool
+	Time
+	ObjectID
+)
+
+// Reserved parameter names
+const (
+	sortParam   = "sort"
+	limitParam  = "limit"
+	offsetParam = "offset"
+	cursorParam = "cursor"
+)
+
+// DefaultMaxLimit is the largest limit accepted when Config.MaxLimit is not set
+const DefaultMaxLimit = 100
+
+// MaxWildcards is the largest number of % wildcards accepted in a like or ilike pattern. MongoDB
+// matches patterns as regular expressions, whose cost grows with every wildcard.
+const MaxWildcards = 4
+
+// Field describes a query-string field of an entity
+type Field struct {
+	// Name is the entity field the parameter refers to; empty uses the parameter name
+	Name string
+	Type Type
+	// Filter allows conditions on the field
+	Filter bool
+	// Sort allows ordering by the field
+	Sort bool
+}
+
+// Config is the per-entity allowlist of query-string fields
+type Config struct {
+	// Fields maps parameter names to fields
+	Fields map[string]Field
+	// DefaultLimit applies when the query has no limit; zero uses types.DefaultPageSize
+	DefaultLimit int
+	// MaxLimit is the largest accepted limit; zero uses DefaultMaxLimit
+	MaxLimit int
+}
+
+// Query is a parsed query string
+type Query struct {
+	Filter  types.Identifier
+	OrderBy types.SortSpec
+	Limit   int
+	Offset  int
+	Cursor  string
+}
+
+// PageRequest returns the query as a keyset pagination request
+func (q Query) PageRequest() types.PageRequest {
+	return types.PageRequest{Where: q.Filter, OrderBy: q.OrderBy, Limit: q.Limit, Cursor: q.Cursor}
+}
+
+// FindOptions returns the sort and limit of the query as find options
+func (q Query) FindOptions() types.FindOptions {
+	return types.FindOptions{OrderBy: q.OrderBy, Limit: q.Limit}
+}
+
+// QueryParams returns the query as offset pagination parameters
+func QueryParams[T any](q Query) types.QueryParams[T] {
+	return types.QueryParams[T]{Where: q.Filter, OrderBy: q.OrderBy, Limit: q.Limit, Offset: q.Offset}
+}
+
+// ParseQuery parses a raw URL query string such as "age[gt]=18&sort=-created_at"
+func ParseQuery(rawQuery string, config Config) (Query, error) {
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return Query{}, fmt.Errorf("%w: malformed query string: %v", repoErrors.ErrInvalidFilter, err)
+	}
+	return Parse(values, config)
+}
+
+// Parse parses query-string values into a backend-neutral identifier and sort and pagination parameters
+func Parse(values url.Values, config Config) (Query, error) {
+	filter := identifier.New()
+	query := Query{Filter: filter, Limit: config.defaultLimit()}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if len(values[key]) != 1 {
+			return Query{}, fmt.Errorf("%w: parameter %q given more than once", repoErrors.ErrInvalidFilter, key)
+		}
+		raw := values[key][0]
+
+		var err error
+		switch key {
+		case sortParam:
+			query.OrderBy, err = parseSort(raw, config)
+		case limitParam:
+			query.Limit, err = parseLimit(raw, config)
+		case offsetParam:
+			query.Offset, err = parseOffset(raw)
+		case cursorParam:
+			query.Cursor = raw
+		default:
+			err = parseCondition(filter, key, raw, config)
+		}
+		if err != nil {
+			return Query{}, err
+		}
+	}
+
+	if query.Cursor != "" && query.Offset > 0 {
+		return Query{}, fmt.Errorf("%w: cursor and offset cannot be combined", repoErrors.ErrInvalidFilter)
+	}
+
+	return query, nil
+}
+
+// parseCondition adds the condition of a field parameter such as "age[gt]" to filter
+func parseCondition(filter *identifier.UnifiedIdentifier, key, raw string, config Config) error {
+	param, op, err := splitKey(key)
+	if err != nil {
+		return err
+	}
+
+	field, ok := config.Filterable(param)
+	if !ok {
+		return fmt.Errorf("%w: field %q is not filterable", repoErrors.ErrInvalidFilter, param)
+	}
+	name := field.Name
+
+	switch op {
+	case "", "eq", "ne", "gt", "gte", "lt", "lte":
+		if op != "" && op != "eq" && op != "ne" && field.Type == Bool {
+			return fmt.Errorf("%w: %s cannot be used on boolean field %q", repoErrors.ErrInvalidFilter, op, param)
+		}
+		value, err := coerce(param, field.Type, raw)
+		if err != nil {
+			return err
+		}
+		switch op {
+		case "", "eq":
+			filter.Equal(name, value)
+		case "ne":
+			filter.NotEqual(name, value)
+		case "gt":
+			filter.GreaterThan(name, value)
+		case "gte":
+			filter.GreaterOrEqual(name, value)
+		case "lt":
+			filter.LessThan(name, value)
+		case "lte":
+			filter.LessOrEqual(name, value)
+		}
+	case "in", "nin", "between":
+		values, err := coerceList(param, field.Type, raw)
+		if err != nil {
+			return err
+		}
+		switch op {
+		case "in":
+			filter.In(name, values)
+		case "nin":
+			filter.NotIn(name, values)
+		case "between":
+			if len(values) != 2 {
+				return fmt.Errorf("%w: between on field %q needs two values", repoErrors.ErrInvalidFilter, param)
+			}
+			filter.Between(name, values[0], values[1])
+		}
+	case "like", "ilike", "prefix", "suffix":
+		if field.Type != String {
+			return fmt.Errorf("%w: %s can only be used on text field %q", repoErrors.ErrInvalidFilter, op, param)
+		}
+		if (op == "like" || op == "ilike") && strings.Count(raw, "%") > MaxWildcards {
+			return fmt.Errorf("%w: %s on field %q allows at most %d wildcards", repoErrors.ErrInvalidFilter, op, param, MaxWildcards)
+		}
+		switch op {
+		case "like":
+			filter.Like(name, raw)
+		case "ilike":
+			filter.ILike(name, raw)
+		case "prefix":
+			filter.StartsWith(name, raw)
+		case "suffix":
+			filter.EndsWith(name, raw)
+		}
+	case "exists", "null":
+		flag, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%w: %s on field %q needs true or false, got %q", repoErrors.ErrInvalidFilter, op, param, raw)
+		}
+		switch {
+		case op == "exists":
+			filter.Exists(name, flag)
+		case flag:
+			filter.IsNull(name)
+		default:
+			filter.IsNotNull(name)
+		}
+	default:
+		return fmt.Errorf("%w: unknown operator %q on field %q", repoErrors.ErrInvalidFilter, op, param)
+	}
+
+	return nil
+}
+
+// splitKey splits "age[gt]" into "age" and "gt"; a key without brackets has no operator
+func splitKey(key string) (string, string, error) {
+	name, rest, ok := strings.Cut(key, "[")
+	if !ok {
+		return key, "", nil
+	}
+
+	op, ok := strings.CutSuffix(rest, "]")
+	if !ok || name == "" || op == "" || strings.ContainsAny(op, "[]") {
+		return "", "", fmt.Errorf("%w: malformed parameter %q", repoErrors.ErrInvalidFilter, key)
+	}
+
+	return name, op, nil
+}
+
+// parseSort parses "-created_at,name" into a sort specification of sortable fields
+func parseSort(raw string, config Config) (types.SortSpec, error) {
+	var spec types.SortSpec
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(raw, ",") {
+		direction := types.SortAsc
+		param := part
+		if trimmed, ok := strings.CutPrefix(part, "-"); ok {
+			direction, param = types.SortDesc, trimmed
+		} else if trimmed, ok := strings.CutPrefix(part, "+"); ok {
+			param = trimmed
+		}
+
+		field, ok := config.Fields[param]
+		if !ok || !field.Sort {
+			return nil, fmt.Errorf("%w: field %q is not sortable", repoErrors.ErrInvalidFilter, param)
+		}
+		if seen[param] {
+			return nil, fmt.Errorf("%w: field %q sorted more than once", repoErrors.ErrInvalidFilter, param)
+		}
+		seen[param] = true
+
+		spec = append(spec, types.SortField{Field: field.name(param), Direction: direction})
+	}
+
+	return spec, nil
+}
+
+// parseLimit parses a page size between 1 and the configured maximum
+func parseLimit(raw string, config Config) (int, error) {
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		return 0, fmt.Errorf("%w: limit must be a positive integer, got %q", repoErrors.ErrInvalidFilter, raw)
+	}
+	if maxLimit := config.maxLimit(); limit > maxLimit {
+		return 0, fmt.Errorf("%w: limit must not exceed %d, got %d", repoErrors.ErrInvalidFilter, maxLimit, limit)
+	}
+	return limit, nil
+}
+
+// parseOffset parses a non-negative offset
+func parseOffset(raw string) (int, error) {
+	offset, err := strconv.Atoi(raw)
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("%w: offset must be a non-negative integer, got %q", repoErrors.ErrInvalidFilter, raw)
+	}
+	return offset, nil
+}
+
+// coerceList converts the comma-separated values of raw to t
+func coerceList(param string, t Type, raw string) ([]interface{}, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("%w: field %q needs at least one value", repoErrors.ErrInvalidFilter, param)
+	}
+
+	parts := strings.Split(raw, ",")
+	values := make([]interface{}, len(parts))
+	for i, part := range parts {
+		value, err := coerce(param, t, part)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// coerce converts raw to t. Times are RFC 3339 timestamps or dates in UTC.
+func coerce(param string, t Type, raw string) (interface{}, error) {
+	var value interface{}
+	var err error
+
+	switch t {
+	case String:
+		return raw, nil
+	case Int:
+		value, err = strconv.Atoi(raw)
+	case Float:
+		value, err = strconv.ParseFloat(raw, 64)
+	case Bool:
+		value, err = strconv.ParseBool(raw)
+	case Time:
+		value, err = time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			value, err = time.Parse(time.DateOnly, raw)
+		}
+	case ObjectID:
+		value, err = primitive.ObjectIDFromHex(raw)
+	default:
+		return nil, fmt.Errorf("%w: field %q has an unknown type", repoErrors.ErrInvalidFilter, param)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid value %q for field %q", repoErrors.ErrInvalidFilter, raw, param)
+	}
+	return value, nil
+}
+
+// Filterable returns the field behind the parameter param if it allows conditions. The
+// returned field's Name is always set.
+func (c Config) Filterable(param string) (Field, bool) {
+	field, ok := c.Fields[param]
+	if !ok || !field.Filter {
+		return Field{}, false
+	}
+	field.Name = field.name(param)
+	return field, true
+}
+
+// Coerce converts raw to the type of the field, reporting unusable values as ErrInvalidFilter
+func (f Field) Coerce(raw string) (interface{}, error) {
+	return coerce(f.Name, f.Type, raw)
+}
+
+// name returns the entity field name of the parameter param
+func (f Field) name(param string) string {
+	if f.Name != "" {
+		return f.Name
+	}
+	return param
+}
+
+// defaultLimit returns the limit used when the query has none
+func (c Config) defaultLimit() int {
+	if c.DefaultLimit > 0 {
+		return c.DefaultLimit
+	}
+	return types.DefaultPageSize
+}
+
+// maxLimit returns the largest accepted limit
+func (c Config) maxLimit() int {
+	if c.MaxLimit > 0 {
+		return c.MaxLimit
+	}
+	return DefaultMaxLimit
+}
@@ -0,0 +1,147 @@
+package httpparse_test
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/query/httpparse"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var userConfig = httpparse.Config{
+	Fields: map[string]httpparse.Field{
+		"name":       {Type: httpparse.String, Filter: true, Sort: true},
+		"age":        {Type: httpparse.Int, Filter: true, Sort: true},
+		"score":      {Type: httpparse.Float, Filter: true},
+		"active":     {Type: httpparse.Bool, Filter: true},
+		"created_at": {Name: "createdAt", Type: httpparse.Time, Filter: true, Sort: true},
+		"owner":      {Name: "ownerId", Type: httpparse.ObjectID, Filter: true},
+		"status":     {Type: httpparse.String, Filter: true},
+	},
+}
+
+func TestParseQuery(t *testing.T) {
+	ownerID := primitive.NewObjectID()
+
+	query, err := httpparse.ParseQuery(
+		"age[gt]=18&status[in]=a,b&active=true&created_at[gte]=2024-05-01&owner="+ownerID.Hex()+
+			"&name[prefix]=Jo&score[between]=1.5,9&sort=-created_at,name&limit=50&offset=100",
+		userConfig,
+	)
+	if err != nil {
+		t.Fatalf("Failed to parse query: %v", err)
+	}
+
+	expected := identifier.New()
+	expected.GreaterThan("age", 18).
+		In("status", []interface{}{"a", "b"}).
+		Equal("active", true).
+		GreaterOrEqual("createdAt", time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)).
+		Equal("ownerId", ownerID).
+		StartsWith("name", "Jo").
+		Between("score", 1.5, 9.0)
+
+	expectedConditions, _ := identifier.Conditions(expected)
+	conditions, err := identifier.Conditions(query.Filter)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(conditions, expectedConditions) {
+		t.Errorf("Expected conditions %v, got %v", expectedConditions, conditions)
+	}
+
+	expectedSort := types.SortSpec{
+		{Field: "createdAt", Direction: types.SortDesc},
+		{Field: "name", Direction: types.SortAsc},
+	}
+	if !reflect.DeepEqual(query.OrderBy, expectedSort) {
+		t.Errorf("Expected sort %v, got %v", expectedSort, query.OrderBy)
+	}
+	if query.Limit != 50 || query.Offset != 100 {
+		t.Errorf("Expected limit 50 and offset 100, got %d and %d", query.Limit, query.Offset)
+	}
+}
+
+func TestParseQuery_Defaults(t *testing.T) {
+	query, err := httpparse.ParseQuery("cursor=abc", userConfig)
+	if err != nil {
+		t.Fatalf("Failed to parse query: %v", err)
+	}
+
+	if query.Limit != types.DefaultPageSize {
+		t.Errorf("Expected default limit %d, got %d", types.DefaultPageSize, query.Limit)
+	}
+
+	page := query.PageRequest()
+	if page.Cursor != "abc" || page.Limit != types.DefaultPageSize {
+		t.Errorf("Expected page request with cursor abc, got %+v", page)
+	}
+
+	conditions, err := identifier.Conditions(query.Filter)
+	if err != nil || len(conditions) != 0 {
+		t.Errorf("Expected no conditions, got %v, %v", conditions, err)
+	}
+}
+
+func TestParseQuery_LikeIsNotARegex(t *testing.T) {
+	pattern := `^(a+)+$.*[x|y]\%`
+	query, err := httpparse.ParseQuery("name[like]="+url.QueryEscape(pattern+"%"), userConfig)
+	if err != nil {
+		t.Fatalf("Failed to parse query: %v", err)
+	}
+
+	expected := map[string]interface{}{"name": bson.M{"$regex": `^\^\(a\+\)\+\$\.\*\[x\|y\]%.*$`}}
+	if got := query.Filter.ToBSON(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestParseQuery_Null(t *testing.T) {
+	query, err := httpparse.ParseQuery("name[null]=true&status[null]=false", userConfig)
+	if err != nil {
+		t.Fatalf("Failed to parse query: %v", err)
+	}
+
+	expected := map[string]interface{}{"name": nil, "status": bson.M{"$ne": nil}}
+	if got := query.Filter.ToBSON(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestParseQuery_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+	}{
+		{"unknown field", "email=a@b.c"},
+		{"unknown operator", "age[near]=3"},
+		{"malformed key", "age[gt=3"},
+		{"invalid int", "age=old"},
+		{"invalid time", "created_at[gt]=yesterday"},
+		{"invalid object id", "owner=42"},
+		{"ordering on bool", "active[gt]=true"},
+		{"text operator on int", "age[like]=1%"},
+		{"too many wildcards", "name[like]=%25a%25a%25a%25a%25"},
+		{"between with one value", "score[between]=1"},
+		{"repeated parameter", "age=1&age=2"},
+		{"unsortable field", "sort=score"},
+		{"sorted twice", "sort=name,-name"},
+		{"limit too large", "limit=1000"},
+		{"negative offset", "offset=-1"},
+		{"cursor with offset", "cursor=abc&offset=20"},
+		{"null without bool", "name[null]=maybe"},
+	}
+
+	for _, tt := range tests {
+		if _, err := httpparse.ParseQuery(tt.query, userConfig); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+			t.Errorf("%s: expected ErrInvalidFilter, got %v", tt.name, err)
+		}
+	}
+}
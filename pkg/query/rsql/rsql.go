@@ -0,0 +1,388 @@
+// Package rsql parses RSQL/FIQL filter expressions such as
+//
+//	name==John*;age=gt=30,role=in=(admin,owner)
+//
+// into backend-neutral identifiers. ";" (AND) binds tighter than "," (OR) and parentheses
+// group. The comparison operators are ==, !=, =lt= (<), =le= (<=), =gt= (>), =ge= (>=),
+// =in=, =out= and =isnull= (true/false). Unquoted text values may use * as a wildcard;
+// quoted values ('...' or "...", with backslash escapes) are taken literally.
+//
+// Parentheses may nest at most MaxDepth levels deep.
+//
+// Fields are checked against an httpparse.Config allowlist and values converted to the field's
+// type. Malformed or rejected expressions are reported as an *Error carrying the byte offset
+// of the problem, which matches ErrInvalidFilter.
+package rsql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/query/httpparse"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+)
+
+// Error is a malformed or rejected expression. Pos is the byte offset it was detected at.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+// Error implements error
+func (e *Error) Error() string {
+	return fmt.Sprintf("rsql: %s at position %d", e.Msg, e.Pos)
+}
+
+// Unwrap makes every parse error match ErrInvalidFilter
+func (e *Error) Unwrap() error {
+	return repoErrors.ErrInvalidFilter
+}
+
+// reserved are the characters that cannot appear in selectors and unquoted values
+const reserved = "\"'();,=<>!~ \t\r\n"
+
+// operators lists the comparison operators, longer spellings first
+var operators = []string{"==", "!=", "<=", ">=", "<", ">", "=lt=", "=le=", "=gt=", "=ge=", "=in=", "=out=", "=isnull="}
+
+// MaxDepth is the deepest parentheses may nest
+const MaxDepth = 32
+
+// Parse parses an RSQL expression into an identifier over the filterable fields of config.
+// An empty expression matches everything.
+func Parse(input string, config httpparse.Config) (types.Identifier, error) {
+	if strings.TrimSpace(input) == "" {
+		return identifier.New(), nil
+	}
+
+	p := &parser{input: input, config: config}
+
+	id, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.input) {
+		return nil, p.errorf("unexpected %q", p.input[p.pos])
+	}
+
+	return id, nil
+}
+
+// parser is a recursive descent parser over the input
+type parser struct {
+	input  string
+	pos    int
+	depth  int
+	config httpparse.Config
+}
+
+// or parses and-expressions separated by ","
+func (p *parser) or() (*identifier.UnifiedIdentifier, error) {
+	return p.list(',', p.and, func(operands []types.Identifier) *identifier.UnifiedIdentifier {
+		id := identifier.New()
+		id.Or(operands...)
+		return id
+	})
+}
+
+// and parses constraints separated by ";"
+func (p *parser) and() (*identifier.UnifiedIdentifier, error) {
+	return p.list(';', p.constraint, func(operands []types.Identifier) *identifier.UnifiedIdentifier {
+		id := identifier.New()
+		id.And(operands...)
+		return id
+	})
+}
+
+// list parses one or more operands separated by sep and combines several with combine
+func (p *parser) list(
+	sep byte,
+	operand func() (*identifier.UnifiedIdentifier, error),
+	combine func([]types.Identifier) *identifier.UnifiedIdentifier,
+) (*identifier.UnifiedIdentifier, error) {
+	var operands []types.Identifier
+	for {
+		id, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, id)
+
+		p.skipSpace()
+		if !p.consume(sep) {
+			break
+		}
+	}
+
+	if len(operands) == 1 {
+		return operands[0].(*identifier.UnifiedIdentifier), nil
+	}
+	return combine(operands), nil
+}
+
+// constraint parses a parenthesized expression or a comparison
+func (p *parser) constraint() (*identifier.UnifiedIdentifier, error) {
+	p.skipSpace()
+	open := p.pos
+	if !p.consume('(') {
+		return p.comparison()
+	}
+
+	if p.depth == MaxDepth {
+		return nil, &Error{Pos: open, Msg: fmt.Sprintf("parentheses nested deeper than %d levels", MaxDepth)}
+	}
+	p.depth++
+	id, err := p.or()
+	p.depth--
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.consume(')') {
+		return nil, p.expected("\")\"")
+	}
+	return id, nil
+}
+
+// comparison parses selector, operator and arguments
+func (p *parser) comparison() (*identifier.UnifiedIdentifier, error) {
+	start := p.pos
+	selector := p.word()
+	if selector == "" {
+		return nil, p.expected("field name")
+	}
+
+	field, ok := p.config.Filterable(selector)
+	if !ok {
+		return nil, &Error{Pos: start, Msg: fmt.Sprintf("field %q is not filterable", selector)}
+	}
+
+	opPos := p.pos
+	op := p.operator()
+	if op == "" {
+		return nil, p.expected("comparison operator")
+	}
+
+	argPos := p.pos
+	args, err := p.arguments()
+	if err != nil {
+		return nil, err
+	}
+
+	multiple := op == "=in=" || op == "=out="
+	if !multiple && len(args) != 1 {
+		return nil, &Error{Pos: argPos, Msg: fmt.Sprintf("operator %s takes a single value", op)}
+	}
+
+	id := identifier.New()
+	switch op {
+	case "=in=", "=out=":
+		values := make([]interface{}, len(args))
+		for i, arg := range args {
+			if values[i], err = p.coerce(field, arg); err != nil {
+				return nil, err
+			}
+		}
+		if op == "=in=" {
+			id.In(field.Name, values)
+		} else {
+			id.NotIn(field.Name, values)
+		}
+	case "=isnull=":
+		isNull, err := strconv.ParseBool(args[0].text)
+		if err != nil {
+			return nil, &Error{Pos: args[0].pos, Msg: fmt.Sprintf("=isnull= needs true or false, got %q", args[0].text)}
+		}
+		if isNull {
+			id.IsNull(field.Name)
+		} else {
+			id.IsNotNull(field.Name)
+		}
+	case "==", "!=":
+		if arg := args[0]; !arg.quoted && field.Type == httpparse.String && strings.Contains(arg.text, "*") {
+			match := identifier.New()
+			addWildcard(match, field.Name, arg.text)
+			if op == "==" {
+				return match, nil
+			}
+			id.Not(match)
+			return id, nil
+		}
+		value, err := p.coerce(field, args[0])
+		if err != nil {
+			return nil, err
+		}
+		if op == "==" {
+			id.Equal(field.Name, value)
+		} else {
+			id.NotEqual(field.Name, value)
+		}
+	default:
+		value, err := p.coerce(field, args[0])
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "<", "=lt=":
+			id.LessThan(field.Name, value)
+		case "<=", "=le=":
+			id.LessOrEqual(field.Name, value)
+		case ">", "=gt=":
+			id.GreaterThan(field.Name, value)
+		case ">=", "=ge=":
+			id.GreaterOrEqual(field.Name, value)
+		default:
+			return nil, &Error{Pos: opPos, Msg: fmt.Sprintf("unsupported operator %s", op)}
+		}
+	}
+
+	return id, nil
+}
+
+// addWildcard adds a condition matching pattern, where * matches any run of characters
+func addWildcard(id *identifier.UnifiedIdentifier, field, pattern string) {
+	trimmed := strings.Trim(pattern, "*")
+	switch {
+	case !strings.Contains(trimmed, "*") && strings.HasSuffix(pattern, "*") && !strings.HasPrefix(pattern, "*"):
+		id.StartsWith(field, trimmed)
+	case !strings.Contains(trimmed, "*") && strings.HasPrefix(pattern, "*") && !strings.HasSuffix(pattern, "*"):
+		id.EndsWith(field, trimmed)
+	default:
+		parts := strings.Split(pattern, "*")
+		for i, part := range parts {
+			parts[i] = regexp.QuoteMeta(part)
+		}
+		id.Regex(field, "^"+strings.Join(parts, ".*")+"$", "s")
+	}
+}
+
+// argument is a value of a comparison
+type argument struct {
+	text   string
+	pos    int
+	quoted bool
+}
+
+// arguments parses a single value or a parenthesized, comma-separated list of values
+func (p *parser) arguments() ([]argument, error) {
+	if !p.consume('(') {
+		arg, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		return []argument{arg}, nil
+	}
+
+	var args []argument
+	for {
+		p.skipSpace()
+		arg, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+
+		p.skipSpace()
+		if p.consume(')') {
+			return args, nil
+		}
+		if !p.consume(',') {
+			return nil, p.expected("\",\" or \")\"")
+		}
+	}
+}
+
+// value parses a quoted or unquoted value
+func (p *parser) value() (argument, error) {
+	start := p.pos
+	if p.pos < len(p.input) && (p.input[p.pos] == '"' || p.input[p.pos] == '\'') {
+		quote := p.input[p.pos]
+		p.pos++
+
+		var b strings.Builder
+		for p.pos < len(p.input) {
+			c := p.input[p.pos]
+			switch {
+			case c == '\\' && p.pos+1 < len(p.input):
+				b.WriteByte(p.input[p.pos+1])
+				p.pos += 2
+			case c == quote:
+				p.pos++
+				return argument{text: b.String(), pos: start, quoted: true}, nil
+			default:
+				b.WriteByte(c)
+				p.pos++
+			}
+		}
+		return argument{}, &Error{Pos: start, Msg: "unterminated quoted value"}
+	}
+
+	text := p.word()
+	if text == "" {
+		return argument{}, p.expected("value")
+	}
+	return argument{text: text, pos: start}, nil
+}
+
+// operator parses a comparison operator, returning "" when there is none
+func (p *parser) operator() string {
+	rest := p.input[p.pos:]
+	for _, op := range operators {
+		if strings.HasPrefix(rest, op) {
+			p.pos += len(op)
+			return op
+		}
+	}
+	return ""
+}
+
+// word consumes a run of unreserved characters
+func (p *parser) word() string {
+	start := p.pos
+	for p.pos < len(p.input) && !strings.ContainsRune(reserved, rune(p.input[p.pos])) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+// coerce converts arg to the type of field
+func (p *parser) coerce(field httpparse.Field, arg argument) (interface{}, error) {
+	value, err := field.Coerce(arg.text)
+	if err != nil {
+		return nil, &Error{Pos: arg.pos, Msg: fmt.Sprintf("invalid value %q for field %q", arg.text, field.Name)}
+	}
+	return value, nil
+}
+
+// consume advances past c if it is the next character
+func (p *parser) consume(c byte) bool {
+	if p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// skipSpace advances past whitespace between tokens
+func (p *parser) skipSpace() {
+	for p.pos < len(p.input) && strings.ContainsRune(" \t\r\n", rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// expected reports that what was wanted is missing at the current position
+func (p *parser) expected(what string) error {
+	if p.pos >= len(p.input) {
+		return p.errorf("expected %s, got end of input", what)
+	}
+	return p.errorf("expected %s, got %q", what, p.input[p.pos])
+}
+
+// errorf reports a problem at the current position
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return &Error{Pos: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,192 @@
+package rsql_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/query/httpparse"
+	"github.com/arash-mosavi/go-base-repository/pkg/query/rsql"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+)
+
+var userConfig = httpparse.Config{
+	Fields: map[string]httpparse.Field{
+		"name":   {Type: httpparse.String, Filter: true},
+		"age":    {Type: httpparse.Int, Filter: true},
+		"role":   {Type: httpparse.String, Filter: true},
+		"active": {Type: httpparse.Bool, Filter: true},
+		"email":  {Name: "emailAddress", Type: httpparse.String, Filter: true},
+	},
+}
+
+// conditions parses input and returns the conditions of the resulting identifier
+func conditions(t *testing.T, input string) []identifier.Condition {
+	t.Helper()
+
+	id, err := rsql.Parse(input, userConfig)
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %v", input, err)
+	}
+	conditions, err := identifier.Conditions(id)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return conditions
+}
+
+// expectedConditions returns the conditions of id
+func expectedConditions(t *testing.T, id types.Identifier) []identifier.Condition {
+	t.Helper()
+
+	conditions, err := identifier.Conditions(id)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return conditions
+}
+
+func TestParse_Precedence(t *testing.T) {
+	got := conditions(t, "name==John*;age=gt=30,role=in=(admin,owner)")
+
+	expected := identifier.New()
+	expected.Or(
+		identifier.New().And(
+			identifier.New().StartsWith("name", "John"),
+			identifier.New().GreaterThan("age", 30),
+		),
+		identifier.New().In("role", []interface{}{"admin", "owner"}),
+	)
+
+	if want := expectedConditions(t, expected); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestParse_Parentheses(t *testing.T) {
+	got := conditions(t, "active==true;(role==admin , role==owner)")
+
+	expected := identifier.New()
+	expected.And(
+		identifier.New().Equal("active", true),
+		identifier.New().Or(
+			identifier.New().Equal("role", "admin"),
+			identifier.New().Equal("role", "owner"),
+		),
+	)
+
+	if want := expectedConditions(t, expected); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestParse_Comparisons(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected types.Identifier
+	}{
+		{"age<18", identifier.New().LessThan("age", 18)},
+		{"age=le=18", identifier.New().LessOrEqual("age", 18)},
+		{"age>=18", identifier.New().GreaterOrEqual("age", 18)},
+		{"age!=18", identifier.New().NotEqual("age", 18)},
+		{"role=out=(guest)", identifier.New().NotIn("role", []interface{}{"guest"})},
+		{"email=isnull=true", identifier.New().IsNull("emailAddress")},
+		{"name==*son", identifier.New().EndsWith("name", "son")},
+		{"name==J*n", identifier.New().Regex("name", "^J.*n$", "s")},
+		{`name=="John*"`, identifier.New().Equal("name", "John*")},
+		{`name=='O\'Brien'`, identifier.New().Equal("name", "O'Brien")},
+		{"name!=Jo*", identifier.New().Not(identifier.New().StartsWith("name", "Jo"))},
+		{"", identifier.New()},
+	}
+
+	for _, tt := range tests {
+		got := conditions(t, tt.input)
+		if want := expectedConditions(t, tt.expected); !reflect.DeepEqual(got, want) {
+			t.Errorf("%q: expected %v, got %v", tt.input, want, got)
+		}
+	}
+}
+
+func TestParse_MaxDepth(t *testing.T) {
+	input := strings.Repeat("(", rsql.MaxDepth) + "age>1" + strings.Repeat(")", rsql.MaxDepth)
+	got := conditions(t, input)
+
+	want := expectedConditions(t, identifier.New().GreaterThan("age", 1))
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestParse_ErrorPositions(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+	}{
+		{"name", 4},
+		{"name==", 6},
+		{"==John", 0},
+		{"name=John", 4},
+		{"name==John;", 11},
+		{"name==John)", 10},
+		{"(name==John", 11},
+		{"name=='John", 6},
+		{"age=gt=old", 7},
+		{"age=gt=(1,2)", 7},
+		{"role=in=(admin,", 15},
+		{"salary==10", 0},
+		{"name==John;salary==10", 11},
+		{"email=isnull=maybe", 13},
+		{strings.Repeat("(", rsql.MaxDepth+1) + "age>1" + strings.Repeat(")", rsql.MaxDepth+1), rsql.MaxDepth},
+		{"name==John;" + strings.Repeat("(", rsql.MaxDepth+1), 11 + rsql.MaxDepth},
+	}
+
+	for _, tt := range tests {
+		_, err := rsql.Parse(tt.input, userConfig)
+
+		var parseErr *rsql.Error
+		if !errors.As(err, &parseErr) {
+			t.Errorf("%q: expected *rsql.Error, got %v", tt.input, err)
+			continue
+		}
+		if parseErr.Pos != tt.pos {
+			t.Errorf("%q: expected position %d, got %d (%v)", tt.input, tt.pos, parseErr.Pos, err)
+		}
+		if !errors.Is(err, repoErrors.ErrInvalidFilter) {
+			t.Errorf("%q: expected ErrInvalidFilter, got %v", tt.input, err)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, seed := range []string{
+		"name==John*;age=gt=30,role=in=(admin,owner)",
+		"(active==true,age<18);email=isnull=false",
+		`name=="a \"quoted\" value"`,
+		"name!=*x*",
+		"((age>1))",
+		strings.Repeat("(", rsql.MaxDepth+1) + "age>1" + strings.Repeat(")", rsql.MaxDepth+1),
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		id, err := rsql.Parse(input, userConfig)
+		if err != nil {
+			var parseErr *rsql.Error
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected *rsql.Error, got %T: %v", err, err)
+			}
+			if parseErr.Pos < 0 || parseErr.Pos > len(input) {
+				t.Fatalf("Error position %d outside input of length %d", parseErr.Pos, len(input))
+			}
+			return
+		}
+
+		if _, err := identifier.Conditions(id); err != nil {
+			t.Fatalf("Parsed %q into an unusable identifier: %v", input, err)
+		}
+	})
+}
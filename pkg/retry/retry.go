@@ -0,0 +1,96 @@
+// Package retry re-runs transactions that failed with a transient error
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy controls how many times a transaction is attempted and how long to wait between attempts
+type Policy struct {
+	// MaxAttempts is the total number of attempts, including the first one. Values below 1 mean a single attempt.
+	MaxAttempts int
+	// BaseDelay is the backoff before the second attempt; it doubles for every further attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff between two attempts
+	MaxDelay time.Duration
+}
+
+// DefaultPolicy returns the policy used by the base repositories unless configured otherwise
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxAttempts: 3,
+		BaseDelay:   20 * time.Millisecond,
+		MaxDelay:    time.Second,
+	}
+}
+
+// Error is returned when an operation still failed after more than one attempt
+type Error struct {
+	Attempts int
+	Err      error
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	return fmt.Sprintf("transaction failed after %d attempts: %v", e.Attempts, e.Err)
+}
+
+// Unwrap returns the error of the last attempt
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Do calls fn until it succeeds, returns an error retryable rejects, the policy runs out of
+// attempts or ctx is done. Failures after more than one attempt are reported as *Error.
+func Do(ctx context.Context, policy Policy, retryable func(error) bool, fn func() error) error {
+	maxAttempts := max(policy.MaxAttempts, 1)
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		if !retryable(err) || attempt == maxAttempts {
+			if attempt == 1 {
+				return err
+			}
+			return &Error{Attempts: attempt, Err: err}
+		}
+
+		timer := time.NewTimer(policy.Backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return &Error{Attempts: attempt, Err: errors.Join(err, ctx.Err())}
+		case <-timer.C:
+		}
+	}
+}
+
+// Backoff returns the delay after the given failed attempt: exponential growth from
+// BaseDelay capped at MaxDelay, with a random jitter of up to half the delay.
+func (p Policy) Backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := 1; i < attempt; i++ {
+		if (p.MaxDelay > 0 && delay >= p.MaxDelay) || delay > math.MaxInt64/2 {
+			break
+		}
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
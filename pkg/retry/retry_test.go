@@ -0,0 +1,120 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/retry"
+)
+
+var errTransient = errors.New("transient")
+
+func isTransient(err error) bool {
+	return errors.Is(err, errTransient)
+}
+
+func TestDo_RetriesTransientErrors(t *testing.T) {
+	policy := retry.Policy{MaxAttempts: 3}
+
+	calls := 0
+	err := retry.Do(context.Background(), policy, isTransient, func() error {
+		calls++
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected success on the third attempt, got %v", err)
+	}
+
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestDo_ReportsAttemptsWhenGivingUp(t *testing.T) {
+	policy := retry.Policy{MaxAttempts: 4}
+
+	calls := 0
+	err := retry.Do(context.Background(), policy, isTransient, func() error {
+		calls++
+		return errTransient
+	})
+
+	var retryErr *retry.Error
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("Expected *retry.Error, got %v", err)
+	}
+
+	if retryErr.Attempts != 4 || calls != 4 {
+		t.Errorf("Expected 4 attempts, got %d (calls %d)", retryErr.Attempts, calls)
+	}
+
+	if !errors.Is(err, errTransient) {
+		t.Error("retry.Error should unwrap to the last error")
+	}
+}
+
+func TestDo_DoesNotRetryPermanentErrors(t *testing.T) {
+	errPermanent := errors.New("permanent")
+
+	calls := 0
+	err := retry.Do(context.Background(), retry.DefaultPolicy(), isTransient, func() error {
+		calls++
+		return errPermanent
+	})
+
+	if err != errPermanent {
+		t.Errorf("Expected the error to be returned unchanged, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestDo_StopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := retry.Policy{MaxAttempts: 5, BaseDelay: time.Hour, MaxDelay: time.Hour}
+
+	calls := 0
+	err := retry.Do(ctx, policy, isTransient, func() error {
+		calls++
+		cancel()
+		return errTransient
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestPolicy_Backoff(t *testing.T) {
+	policy := retry.Policy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+	tests := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{1, 5 * time.Millisecond, 10 * time.Millisecond},
+		{2, 10 * time.Millisecond, 20 * time.Millisecond},
+		{3, 20 * time.Millisecond, 40 * time.Millisecond},
+		{10, 25 * time.Millisecond, 50 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 100; i++ {
+			delay := policy.Backoff(tt.attempt)
+			if delay < tt.min || delay > tt.max {
+				t.Fatalf("Backoff(%d) = %v, want between %v and %v", tt.attempt, delay, tt.min, tt.max)
+			}
+		}
+	}
+}
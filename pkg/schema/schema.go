@@ -0,0 +1,607 @@
+// Package schema describes the queryable fields of an entity, derived from its struct tags, so
+// repositories can reject conditions, sort orders and projections that name fields the entity
+// does not have or compare them with values of the wrong type.
+//
+// A field can be named by its stored name (the bson key or column), its Go field name or its json
+// tag name; the repositories resolve every name to the stored one before building a query.
+package schema
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	gormSchema "gorm.io/gorm/schema"
+)
+
+// Field is a queryable field of an entity
+type Field struct {
+	// Name is the stored name of the field: its BSON key or column
+	Name string
+	// Type is the Go type of the field without pointers, or nil when any value is accepted
+	Type reflect.Type
+}
+
+// Schema is the set of queryable fields of an entity. A nil Schema accepts every field.
+type Schema struct {
+	fields  map[string]Field
+	aliases map[string]string
+	// open schemas accept unknown fields, such as those of an inline map
+	open bool
+	// nested reports whether dotted paths descend into document fields
+	nested bool
+}
+
+// bsonSchemas caches the schemas built by FromBSON by type
+var bsonSchemas sync.Map
+
+// gormSchemas caches the schemas built by FromGORM by parsed GORM schema
+var gormSchemas sync.Map
+
+// FromBSON returns the schema of the documents t encodes to, following the bson struct tags
+// the MongoDB driver uses. Dotted paths reach into embedded documents and arrays of them,
+// "_id" is always accepted and a struct with an inline map accepts any field.
+func FromBSON(t reflect.Type) *Schema {
+	t = indirect(t)
+	if cached, ok := bsonSchemas.Load(t); ok {
+		return cached.(*Schema)
+	}
+
+	s := newSchema()
+	s.nested = true
+	if t.Kind() == reflect.Struct {
+		s.addBSONFields(t)
+	} else {
+		s.open = true
+	}
+	if _, ok := s.fields["_id"]; !ok {
+		s.fields["_id"] = Field{Name: "_id"}
+	}
+
+	cached, _ := bsonSchemas.LoadOrStore(t, s)
+	return cached.(*Schema)
+}
+
+// addBSONFields adds the encoded fields of the struct t, flattening inline structs
+func (s *Schema) addBSONFields(t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		tags, err := bsoncodec.DefaultStructTagParser.ParseStructTags(sf)
+		if err != nil || tags.Skip {
+			continue
+		}
+
+		if tags.Inline {
+			switch fieldType := indirect(sf.Type); fieldType.Kind() {
+			case reflect.Map:
+				s.open = true
+			case reflect.Struct:
+				s.addBSONFields(fieldType)
+			}
+			continue
+		}
+
+		s.add(Field{Name: tags.Name, Type: valueType(sf.Type)}, sf.Name, jsonName(sf))
+	}
+}
+
+// FromGORM returns the schema of the columns of s, so fields are named as GORM names columns
+// and typed by their Go fields. Fields using a GORM serializer accept any value.
+func FromGORM(s *gormSchema.Schema) *Schema {
+	if cached, ok := gormSchemas.Load(s); ok {
+		return cached.(*Schema)
+	}
+
+	fields := newSchema()
+	for _, f := range s.Fields {
+		if f.DBName == "" {
+			continue
+		}
+
+		field := Field{Name: f.DBName, Type: valueType(f.FieldType)}
+		if f.Serializer != nil {
+			field.Type = nil
+		}
+		fields.add(field, f.Name, jsonName(f.StructField))
+	}
+
+	cached, _ := gormSchemas.LoadOrStore(s, fields)
+	return cached.(*Schema)
+}
+
+// newSchema returns an empty schema
+func newSchema() *Schema {
+	return &Schema{fields: make(map[string]Field), aliases: make(map[string]string)}
+}
+
+// add adds field, which can also be named by aliases. An alias shared by two fields names neither.
+func (s *Schema) add(field Field, aliases ...string) {
+	if _, ok := s.fields[field.Name]; ok {
+		return
+	}
+	s.fields[field.Name] = field
+
+	for _, alias := range aliases {
+		if alias == "" || alias == field.Name {
+			continue
+		}
+		if other, ok := s.aliases[alias]; ok && other != field.Name {
+			s.aliases[alias] = ""
+			continue
+		}
+		s.aliases[alias] = field.Name
+	}
+}
+
+// Lookup resolves name, a stored name, Go field name or json tag name, to its field
+func (s *Schema) Lookup(name string) (Field, bool) {
+	if s == nil {
+		return Field{Name: name}, true
+	}
+
+	if field, ok := s.field(name); ok {
+		return field, true
+	}
+	if !s.nested {
+		return Field{}, false
+	}
+
+	head, rest, ok := strings.Cut(name, ".")
+	if !ok {
+		return Field{}, false
+	}
+	parent, ok := s.field(head)
+	if !ok {
+		return Field{}, false
+	}
+
+	child, ok := lookupPath(parent.Type, rest)
+	if !ok {
+		return Field{}, false
+	}
+	child.Name = parent.Name + "." + child.Name
+	return child, true
+}
+
+// field resolves name without descending into nested documents
+func (s *Schema) field(name string) (Field, bool) {
+	if field, ok := s.fields[name]; ok {
+		return field, true
+	}
+	if stored := s.aliases[name]; stored != "" {
+		return s.fields[stored], true
+	}
+	if s.open && name != "" {
+		return Field{Name: name}, true
+	}
+	return Field{}, false
+}
+
+// lookupPath resolves the dotted path inside a value of type t. Numeric segments index arrays,
+// other segments name fields of the array elements as MongoDB does.
+func lookupPath(t reflect.Type, path string) (Field, bool) {
+	if t == nil || t.Kind() == reflect.Map {
+		return Field{Name: path}, true
+	}
+
+	segment, rest, more := strings.Cut(path, ".")
+	switch {
+	case isArray(t):
+		if _, err := strconv.Atoi(segment); err == nil {
+			if !more {
+				return Field{Name: segment, Type: valueType(t.Elem())}, true
+			}
+			child, ok := lookupPath(valueType(t.Elem()), rest)
+			child.Name = segment + "." + child.Name
+			return child, ok
+		}
+		return lookupPath(valueType(t.Elem()), path)
+	case isDocument(t):
+		child, ok := FromBSON(t).Lookup(path)
+		return child, ok
+	}
+
+	return Field{}, false
+}
+
+// Conditions resolves the fields of conditions, including those inside groups, to their stored
+// names and checks each value against the type of its field. Unknown fields and unusable values
+// are reported as ErrInvalidFilter naming the field.
+func (s *Schema) Conditions(conditions []identifier.Condition) ([]identifier.Condition, error) {
+	if s == nil || len(conditions) == 0 {
+		return conditions, nil
+	}
+
+	resolved := make([]identifier.Condition, len(conditions))
+	for i, c := range conditions {
+		if c.IsGroup() {
+			operands := make([][]identifier.Condition, len(c.Operands))
+			for j, operand := range c.Operands {
+				var err error
+				if operands[j], err = s.Conditions(operand); err != nil {
+					return nil, err
+				}
+			}
+			c.Operands = operands
+			resolved[i] = c
+			continue
+		}
+
+		field, ok := s.Lookup(c.Field)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", repoErrors.ErrInvalidFilter, c.Field)
+		}
+		if err := checkValue(field, c); err != nil {
+			return nil, err
+		}
+		c.Field = field.Name
+		resolved[i] = c
+	}
+
+	return resolved, nil
+}
+
+// Sort resolves the fields of sort to their stored names
+func (s *Schema) Sort(sort types.SortSpec) (types.SortSpec, error) {
+	if s == nil || len(sort) == 0 {
+		return sort, nil
+	}
+
+	resolved := make(types.SortSpec, len(sort))
+	for i, field := range sort {
+		if field.Field != "" {
+			stored, ok := s.Lookup(field.Field)
+			if !ok {
+				return nil, fmt.Errorf("%w: unknown sort field %q", repoErrors.ErrInvalidFilter, field.Field)
+			}
+			field.Field = stored.Name
+		}
+		resolved[i] = field
+	}
+
+	return resolved, nil
+}
+
+// Fields resolves names, such as the fields of a projection, to their stored names
+func (s *Schema) Fields(names []string) ([]string, error) {
+	if s == nil || len(names) == 0 {
+		return names, nil
+	}
+
+	resolved := make([]string, len(names))
+	for i, name := range names {
+		field, ok := s.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", repoErrors.ErrInvalidFilter, name)
+		}
+		resolved[i] = field.Name
+	}
+
+	return resolved, nil
+}
+
+// Names returns the stored names of the top-level fields in sorted order. Fields accepted only
+// because the schema is open are not included.
+func (s *Schema) Names() []string {
+	if s == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(s.fields))
+	for name := range s.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Updates resolves the fields of changes to their stored names and checks each change against
+// the type of its field. Unknown fields, unknown operators and unusable values are reported as
+// ErrInvalidUpdate naming the field.
+func (s *Schema) Updates(changes types.UpdateSet) (types.UpdateSet, error) {
+	resolved := make(types.UpdateSet, len(changes))
+	for name, value := range changes {
+		field, ok := s.Lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", repoErrors.ErrInvalidUpdate, name)
+		}
+		if err := checkUpdate(field, name, types.AsFieldUpdate(value)); err != nil {
+			return nil, err
+		}
+		if _, ok := resolved[field.Name]; ok {
+			return nil, fmt.Errorf("%w: field %q is changed more than once", repoErrors.ErrInvalidUpdate, field.Name)
+		}
+		resolved[field.Name] = value
+	}
+
+	return resolved, nil
+}
+
+// versionField names the field holding the version of types.Versioned entities
+const versionField = "version"
+
+// Version returns the stored name of the version field of types.Versioned entities, named
+// "version" or Version
+func (s *Schema) Version() (string, error) {
+	for _, name := range []string{versionField, "Version"} {
+		if field, ok := s.Lookup(name); ok {
+			return field.Name, nil
+		}
+	}
+	return "", fmt.Errorf("versioned entity has no %q field", versionField)
+}
+
+// ExpectedVersion takes the version the caller expects the entity to have, given as a change to
+// the version field, out of changes. It reports false when changes do not name the version field.
+func (s *Schema) ExpectedVersion(changes types.UpdateSet) (types.UpdateSet, int64, bool, error) {
+	version, err := s.Version()
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	for name, value := range changes {
+		if field, ok := s.Lookup(name); !ok || field.Name != version {
+			continue
+		}
+
+		update := types.AsFieldUpdate(value)
+		expected, ok := integer(update.Value)
+		if update.Operator != types.OpSet || !ok {
+			return nil, 0, false, fmt.Errorf("%w: field %q must be set to the expected version", repoErrors.ErrInvalidUpdate, name)
+		}
+
+		rest := make(types.UpdateSet, len(changes)-1)
+		for other, value := range changes {
+			if other != name {
+				rest[other] = value
+			}
+		}
+		return rest, expected, true, nil
+	}
+
+	return changes, 0, false, nil
+}
+
+// ExpectedVersionCondition takes the version the caller expects the entity to have, given as a
+// top-level equality condition on the version field, out of conditions, which must already be
+// resolved to stored names. It reports false when no condition compares the version field.
+func (s *Schema) ExpectedVersionCondition(conditions []identifier.Condition) ([]identifier.Condition, int64, bool, error) {
+	version, err := s.Version()
+	if err != nil {
+		return nil, 0, false, err
+	}
+
+	for i, c := range conditions {
+		if c.IsGroup() || c.Field != version || c.Operator != identifier.OpEqual {
+			continue
+		}
+
+		expected, ok := integer(c.Value)
+		if !ok {
+			return nil, 0, false, fmt.Errorf("%w: field %q must equal the expected version", repoErrors.ErrInvalidFilter, c.Field)
+		}
+
+		rest := make([]identifier.Condition, 0, len(conditions)-1)
+		rest = append(rest, conditions[:i]...)
+		rest = append(rest, conditions[i+1:]...)
+		return rest, expected, true, nil
+	}
+
+	return conditions, 0, false, nil
+}
+
+// integer returns value as an int64 when it is an integer
+func integer(value interface{}) (int64, bool) {
+	v := reflect.ValueOf(value)
+	switch {
+	case v.CanInt():
+		return v.Int(), true
+	case v.CanUint():
+		return int64(v.Uint()), true
+	}
+	return 0, false
+}
+
+// checkUpdate checks that update can be applied to field, named name by the caller
+func checkUpdate(field Field, name string, update types.FieldUpdate) error {
+	switch update.Operator {
+	case types.OpSet, types.OpMin, types.OpMax:
+		if update.Value == nil || field.Type == nil || assignable(reflect.TypeOf(update.Value), field.Type) {
+			return nil
+		}
+	case types.OpUnset:
+		return nil
+	case types.OpIncrement, types.OpMultiply:
+		if field.Type != nil && !isNumber(field.Type) && !convertsItself(field.Type) && field.Type.Kind() != reflect.Interface {
+			return fmt.Errorf("%w: %s cannot be used on field %q of type %s", repoErrors.ErrInvalidUpdate, update.Operator, name, field.Type)
+		}
+		if update.Value != nil && isNumber(indirect(reflect.TypeOf(update.Value))) {
+			return nil
+		}
+	case types.OpPush, types.OpPull:
+		if field.Type != nil && !isArray(field.Type) && !convertsItself(field.Type) && field.Type.Kind() != reflect.Interface {
+			return fmt.Errorf("%w: %s cannot be used on field %q of type %s", repoErrors.ErrInvalidUpdate, update.Operator, name, field.Type)
+		}
+		values, ok := update.Value.([]interface{})
+		if !ok {
+			break
+		}
+		element := field.Type
+		if element != nil && isArray(element) {
+			element = valueType(element.Elem())
+		}
+		for _, value := range values {
+			if value != nil && element != nil && !compatible(reflect.TypeOf(value), element) {
+				return fmt.Errorf("%w: value of type %T does not match the elements of field %q of type %s", repoErrors.ErrInvalidUpdate, value, name, field.Type)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown operator %q on field %q", repoErrors.ErrInvalidUpdate, update.Operator, name)
+	}
+
+	if field.Type == nil {
+		return fmt.Errorf("%w: value of type %T cannot be used with %s on field %q", repoErrors.ErrInvalidUpdate, update.Value, update.Operator, name)
+	}
+	return fmt.Errorf("%w: value of type %T cannot be used with %s on field %q of type %s", repoErrors.ErrInvalidUpdate, update.Value, update.Operator, name, field.Type)
+}
+
+// assignable reports whether values of type v can be stored in a field of type t
+func assignable(v, t reflect.Type) bool {
+	if isArray(t) && !isArray(indirect(v)) && !convertsItself(t) {
+		return false
+	}
+	return compatible(v, t)
+}
+
+// checkValue checks that the value of c can be compared with field
+func checkValue(field Field, c identifier.Condition) error {
+	if field.Type == nil {
+		return nil
+	}
+
+	switch c.Operator {
+	case identifier.OpIsNull, identifier.OpIsNotNull, identifier.OpExists:
+		return nil
+	case identifier.OpLike, identifier.OpILike, identifier.OpStartsWith, identifier.OpEndsWith, identifier.OpRegex:
+		if !isText(field.Type) {
+			return fmt.Errorf("%w: %s cannot be used on field %q of type %s", repoErrors.ErrInvalidFilter, c.Operator, c.Field, field.Type)
+		}
+		return nil
+	case identifier.OpIn, identifier.OpNotIn, identifier.OpBetween:
+		list := reflect.ValueOf(c.Value)
+		for i := 0; i < list.Len(); i++ {
+			if err := checkCompatible(field, c, list.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return checkCompatible(field, c, c.Value)
+}
+
+// checkCompatible reports a value that cannot be compared with field
+func checkCompatible(field Field, c identifier.Condition, value interface{}) error {
+	if value == nil || compatible(reflect.TypeOf(value), field.Type) {
+		return nil
+	}
+	return fmt.Errorf("%w: value of type %T does not match field %q of type %s", repoErrors.ErrInvalidFilter, value, c.Field, field.Type)
+}
+
+// Types that convert their own values, so any value may be meant for them
+var (
+	valuerType         = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType        = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	valueMarshalerType = reflect.TypeOf((*bson.ValueMarshaler)(nil)).Elem()
+	marshalerType      = reflect.TypeOf((*bson.Marshaler)(nil)).Elem()
+	timeType           = reflect.TypeOf(time.Time{})
+)
+
+// compatible reports whether values of type v can be compared with a field of type t
+func compatible(v, t reflect.Type) bool {
+	v = indirect(v)
+	switch {
+	case v == t, t.Kind() == reflect.Interface, convertsItself(t), convertsItself(v):
+		return true
+	case isNumber(v) && isNumber(t):
+		return true
+	case v.Kind() == reflect.String && t.Kind() == reflect.String, v.Kind() == reflect.Bool && t.Kind() == reflect.Bool:
+		return true
+	case isArray(v) && isArray(t):
+		return compatible(v.Elem(), valueType(t.Elem()))
+	case isArray(t):
+		// MongoDB matches a scalar against the elements of an array
+		return compatible(v, valueType(t.Elem()))
+	case isDocument(t):
+		return v.Kind() == reflect.Map || v.Kind() == reflect.Slice && v == reflect.TypeOf(bson.D{})
+	}
+	return false
+}
+
+// convertsItself reports whether t, or a pointer to it, converts its own database values
+func convertsItself(t reflect.Type) bool {
+	for _, iface := range []reflect.Type{valuerType, scannerType, valueMarshalerType, marshalerType} {
+		if t.Implements(iface) || reflect.PointerTo(t).Implements(iface) {
+			return true
+		}
+	}
+	return false
+}
+
+// valueType returns the type stored for a field declared as t, or nil when any value is accepted
+func valueType(t reflect.Type) reflect.Type {
+	t = indirect(t)
+	if t.Kind() == reflect.Interface {
+		return nil
+	}
+	return t
+}
+
+// indirect removes the pointers from t
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isNumber reports whether t is an integer or floating point type
+func isNumber(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// isText reports whether t holds strings, or arrays of them
+func isText(t reflect.Type) bool {
+	if isArray(t) {
+		return isText(indirect(t.Elem()))
+	}
+	return t.Kind() == reflect.String || t.Kind() == reflect.Interface || convertsItself(t)
+}
+
+// isArray reports whether t is a slice or array other than raw bytes and fixed-size identifiers
+func isArray(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+	case reflect.Array:
+		return t.Elem().Kind() != reflect.Uint8
+	}
+	return false
+}
+
+// isDocument reports whether t is a struct stored as an embedded document rather than a value
+func isDocument(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType && !convertsItself(t) &&
+		t.PkgPath() != "go.mongodb.org/mongo-driver/bson/primitive"
+}
+
+// jsonName returns the name of sf in its json tag, if any
+func jsonName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,356 @@
+package schema_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	repoErrors "github.com/arash-mosavi/go-base-repository/pkg/errors"
+	"github.com/arash-mosavi/go-base-repository/pkg/identifier"
+	"github.com/arash-mosavi/go-base-repository/pkg/schema"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"gorm.io/gorm"
+	gormSchema "gorm.io/gorm/schema"
+)
+
+type address struct {
+	City string `bson:"city"`
+	Zip  string `bson:"zip"`
+}
+
+type audit struct {
+	CreatedAt time.Time `bson:"createdAt" json:"created_at"`
+}
+
+type mongoUser struct {
+	ID        primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	Name      string                 `bson:"name" json:"name"`
+	Age       int                    `bson:"age"`
+	Nickname  *string                `bson:"nick,omitempty" json:"nickname"`
+	Address   address                `bson:"address"`
+	Previous  []address              `bson:"previous"`
+	Tags      []string               `bson:"tags"`
+	Meta      map[string]interface{} `bson:"meta"`
+	Audit     audit                  `bson:",inline"`
+	Secret    string                 `bson:"-"`
+	Untagged  bool
+	unexposed string
+}
+
+type postgresUser struct {
+	gorm.Model
+	Email    string `gorm:"column:email_address" json:"email"`
+	Age      int
+	Settings map[string]string `gorm:"serializer:json"`
+}
+
+// conditions returns the conditions of id
+func conditions(t *testing.T, id types.Identifier) []identifier.Condition {
+	t.Helper()
+
+	conditions, err := identifier.Conditions(id)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return conditions
+}
+
+func TestFromBSON_Lookup(t *testing.T) {
+	s := schema.FromBSON(reflect.TypeOf(&mongoUser{}))
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"name", "name"},
+		{"Name", "name"},
+		{"nickname", "nick"},
+		{"Nickname", "nick"},
+		{"_id", "_id"},
+		{"id", "_id"},
+		{"createdAt", "createdAt"},
+		{"created_at", "createdAt"},
+		{"untagged", "untagged"},
+		{"address.city", "address.city"},
+		{"Address.City", "address.city"},
+		{"previous.zip", "previous.zip"},
+		{"previous.0.zip", "previous.0.zip"},
+		{"tags.1", "tags.1"},
+		{"meta.anything.at.all", "meta.anything.at.all"},
+	}
+	for _, tt := range tests {
+		field, ok := s.Lookup(tt.name)
+		if !ok {
+			t.Errorf("Expected field %q to be found", tt.name)
+			continue
+		}
+		if field.Name != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, field.Name)
+		}
+	}
+
+	for _, name := range []string{"nmae", "secret", "Secret", "unexposed", "audit", "address.street", "age.value", ""} {
+		if _, ok := s.Lookup(name); ok {
+			t.Errorf("Expected field %q to be unknown", name)
+		}
+	}
+}
+
+func TestFromBSON_InlineMapAcceptsAnyField(t *testing.T) {
+	type document struct {
+		Name  string                 `bson:"name"`
+		Extra map[string]interface{} `bson:",inline"`
+	}
+	s := schema.FromBSON(reflect.TypeOf(document{}))
+
+	if field, ok := s.Lookup("whatever"); !ok || field.Type != nil {
+		t.Errorf("Expected an untyped field, got %v, %v", field, ok)
+	}
+}
+
+func TestSchema_Names(t *testing.T) {
+	s := schema.FromBSON(reflect.TypeOf(mongoUser{}))
+
+	expected := []string{"_id", "address", "age", "createdAt", "meta", "name", "nick", "previous", "tags", "untagged"}
+	if got := s.Names(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+}
+
+func TestSchema_Conditions(t *testing.T) {
+	s := schema.FromBSON(reflect.TypeOf(&mongoUser{}))
+
+	filter := identifier.New()
+	filter.Equal("Name", "john").GreaterThan("age", int64(18)).In("tags", []interface{}{"a", "b"})
+	filter.Or(
+		identifier.New().Equal("created_at", time.Now()),
+		identifier.New().StartsWith("address.city", "Ber"),
+	)
+
+	resolved, err := s.Conditions(conditions(t, filter))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	fields := []string{resolved[0].Field, resolved[1].Field, resolved[2].Field,
+		resolved[3].Operands[0][0].Field, resolved[3].Operands[1][0].Field}
+	expected := []string{"name", "age", "tags", "createdAt", "address.city"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Expected fields %v, got %v", expected, fields)
+	}
+}
+
+func TestSchema_ConditionsRejected(t *testing.T) {
+	s := schema.FromBSON(reflect.TypeOf(&mongoUser{}))
+
+	tests := []struct {
+		name   string
+		filter types.Identifier
+		field  string
+	}{
+		{"unknown field", identifier.New().Equal("emial", "x"), "emial"},
+		{"string for int", identifier.New().Equal("age", "18"), "age"},
+		{"string for ObjectID", identifier.New().Equal("_id", "64b7f0c2a1b2c3d4e5f60718"), "_id"},
+		{"string for time", identifier.New().GreaterThan("createdAt", "2024-01-01"), "createdAt"},
+		{"like on int", identifier.New().Like("age", "1%"), "age"},
+		{"mistyped list element", identifier.New().In("age", []interface{}{1, "two"}), "age"},
+		{"mistyped bound", identifier.New().Between("age", 1, true), "age"},
+		{"unknown field in group", identifier.New().Not(identifier.New().Equal("nmae", "x")), "nmae"},
+	}
+
+	for _, tt := range tests {
+		_, err := s.Conditions(conditions(t, tt.filter))
+		if !errors.Is(err, repoErrors.ErrInvalidFilter) {
+			t.Errorf("%s: expected ErrInvalidFilter, got %v", tt.name, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), `"`+tt.field+`"`) {
+			t.Errorf("%s: expected the error to name %q, got %v", tt.name, tt.field, err)
+		}
+	}
+}
+
+func TestSchema_ConditionsAccepted(t *testing.T) {
+	s := schema.FromBSON(reflect.TypeOf(&mongoUser{}))
+
+	filters := []types.Identifier{
+		identifier.New().Equal("age", 18.5),
+		identifier.New().Equal("nick", nil),
+		identifier.New().Equal("tags", "go"),
+		identifier.New().Equal("tags", []string{"go", "mongo"}),
+		identifier.New().Equal("address", map[string]interface{}{"city": "Berlin"}),
+		identifier.New().Equal("meta.score", 3),
+		identifier.New().IsNull("address.zip"),
+		identifier.New().Regex("tags", "^g", "i"),
+	}
+
+	for _, filter := range filters {
+		if _, err := s.Conditions(conditions(t, filter)); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+	}
+}
+
+func TestSchema_Sort(t *testing.T) {
+	s := schema.FromBSON(reflect.TypeOf(&mongoUser{}))
+
+	sort, err := s.Sort(types.SortSpec{{Field: "created_at", Direction: types.SortDesc}, {Field: "Name", Direction: types.SortAsc}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := types.SortSpec{{Field: "createdAt", Direction: types.SortDesc}, {Field: "name", Direction: types.SortAsc}}
+	if !reflect.DeepEqual(sort, expected) {
+		t.Errorf("Expected %v, got %v", expected, sort)
+	}
+
+	if _, err := s.Sort(types.SortSpec{{Field: "name; DROP TABLE users", Direction: types.SortAsc}}); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+		t.Errorf("Expected ErrInvalidFilter, got %v", err)
+	}
+}
+
+func TestSchema_Updates(t *testing.T) {
+	s := schema.FromBSON(reflect.TypeOf(&mongoUser{}))
+
+	changes := types.NewUpdateSet().
+		Set("Name", "john").
+		Unset("nickname").
+		Increment("age", 1).
+		Max("created_at", time.Now()).
+		Push("tags", "a", "b").
+		Set("address.city", "Berlin")
+
+	resolved, err := s.Updates(changes)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for _, field := range []string{"name", "nick", "age", "createdAt", "tags", "address.city"} {
+		if _, ok := resolved[field]; !ok {
+			t.Errorf("Expected field %q in %v", field, resolved)
+		}
+	}
+	if update := types.AsFieldUpdate(resolved["age"]); update.Operator != types.OpIncrement || update.Value != 1 {
+		t.Errorf("Expected increment by 1, got %+v", update)
+	}
+}
+
+func TestSchema_UpdatesRejected(t *testing.T) {
+	s := schema.FromBSON(reflect.TypeOf(&mongoUser{}))
+
+	tests := []struct {
+		name    string
+		changes types.UpdateSet
+		field   string
+	}{
+		{"unknown field", types.NewUpdateSet().Set("emial", "x"), "emial"},
+		{"string for int", types.NewUpdateSet().Set("age", "18"), "age"},
+		{"scalar for array", types.NewUpdateSet().Set("tags", "go"), "tags"},
+		{"increment text", types.NewUpdateSet().Increment("name", 1), "name"},
+		{"increment by text", types.NewUpdateSet().Increment("age", "1"), "age"},
+		{"push to scalar", types.NewUpdateSet().Push("age", 1), "age"},
+		{"mistyped element", types.NewUpdateSet().Pull("tags", 1), "tags"},
+		{"unknown operator", types.UpdateSet{"age": types.FieldUpdate{Operator: "pop"}}, "age"},
+		{"changed twice", types.NewUpdateSet().Set("name", "a").Set("Name", "b"), "name"},
+	}
+
+	for _, tt := range tests {
+		_, err := s.Updates(tt.changes)
+		if !errors.Is(err, repoErrors.ErrInvalidUpdate) {
+			t.Errorf("%s: expected ErrInvalidUpdate, got %v", tt.name, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), `"`+tt.field+`"`) {
+			t.Errorf("%s: expected the error to name %q, got %v", tt.name, tt.field, err)
+		}
+	}
+}
+
+func TestSchema_ExpectedVersion(t *testing.T) {
+	type versionedUser struct {
+		Name    string `bson:"name"`
+		Version int64  `bson:"_v"`
+	}
+	s := schema.FromBSON(reflect.TypeOf(&versionedUser{}))
+
+	if version, err := s.Version(); err != nil || version != "_v" {
+		t.Errorf("Expected version field _v, got %q, %v", version, err)
+	}
+
+	rest, expected, ok, err := s.ExpectedVersion(types.NewUpdateSet().Set("name", "john").Set("Version", 3))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !ok || expected != 3 {
+		t.Errorf("Expected version 3, got %d, %v", expected, ok)
+	}
+	if !reflect.DeepEqual(rest, types.UpdateSet{"name": "john"}) {
+		t.Errorf("Expected the version to be removed, got %v", rest)
+	}
+
+	if _, _, ok, _ := s.ExpectedVersion(types.NewUpdateSet().Set("name", "john")); ok {
+		t.Error("Expected no version without a version change")
+	}
+	if _, _, _, err := s.ExpectedVersion(types.NewUpdateSet().Increment("_v", 1)); !errors.Is(err, repoErrors.ErrInvalidUpdate) {
+		t.Errorf("Expected ErrInvalidUpdate, got %v", err)
+	}
+
+	filter := conditions(t, identifier.New().Equal("name", "john").Equal("_v", 4))
+	remaining, expected, ok, err := s.ExpectedVersionCondition(filter)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !ok || expected != 4 {
+		t.Errorf("Expected version 4, got %d, %v", expected, ok)
+	}
+	if len(remaining) != 1 || remaining[0].Field != "name" {
+		t.Errorf("Expected the version condition to be removed, got %v", remaining)
+	}
+	if _, _, ok, _ := s.ExpectedVersionCondition(remaining); ok {
+		t.Error("Expected no version without a version condition")
+	}
+
+	if _, err := schema.FromBSON(reflect.TypeOf(&address{})).Version(); err == nil {
+		t.Error("Expected an error for an entity without a version field")
+	}
+}
+
+func TestFromGORM(t *testing.T) {
+	parsed, err := gormSchema.Parse(&postgresUser{}, &sync.Map{}, gormSchema.NamingStrategy{})
+	if err != nil {
+		t.Fatalf("Failed to parse schema: %v", err)
+	}
+	s := schema.FromGORM(parsed)
+
+	fields, err := s.Fields([]string{"id", "CreatedAt", "email", "email_address", "Email", "age"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	expected := []string{"id", "created_at", "email_address", "email_address", "email_address", "age"}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Expected %v, got %v", expected, fields)
+	}
+
+	if _, ok := s.Lookup("users.email"); ok {
+		t.Error("Expected dotted names to be unknown for columns")
+	}
+
+	if _, err := s.Conditions(conditions(t, identifier.New().Equal("settings", "anything"))); err != nil {
+		t.Errorf("Expected serialized columns to accept any value, got %v", err)
+	}
+	if _, err := s.Conditions(conditions(t, identifier.New().IsNull("deleted_at").Equal("age", "x"))); !errors.Is(err, repoErrors.ErrInvalidFilter) {
+		t.Errorf("Expected ErrInvalidFilter, got %v", err)
+	}
+}
+
+func TestNilSchemaAcceptsEverything(t *testing.T) {
+	var s *schema.Schema
+
+	filter := conditions(t, identifier.New().Equal("anything", 1))
+	resolved, err := s.Conditions(filter)
+	if err != nil || !reflect.DeepEqual(resolved, filter) {
+		t.Errorf("Expected conditions to pass through, got %v, %v", resolved, err)
+	}
+}
@@ -1,9 +1,17 @@
 package types
 
 import (
+	"database/sql"
+	"sort"
+	"time"
+
 	mongoUOW "github.com/arash-mosavi/mongo-unit-of-work-system/pkg/persistence"
 	postgresDomain "github.com/arash-mosavi/postgrs-unit-of-work-system/pkg/domain"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.mongodb.org/mongo-driver/mongo/writeconcern"
 )
 
 // MongoID represents MongoDB ObjectID
@@ -22,6 +30,14 @@ type PostgresEntity interface {
 	postgresDomain.BaseModel
 }
 
+// Versioned is implemented by entities that opt in to optimistic locking. The version is stored
+// in the entity's "version" field; Update, Patch and SoftDelete check it against the stored one
+// and increment it, failing with ErrConflict when another write got there first.
+type Versioned interface {
+	GetVersion() int64
+	SetVersion(version int64)
+}
+
 // Identifier represents query identifiers for both database types
 type Identifier interface {
 	// MongoDB methods
@@ -38,6 +54,17 @@ type Identifier interface {
 	Between(field string, min, max interface{}) Identifier
 	Like(field string, pattern string) Identifier
 	In(field string, values []interface{}) Identifier
+	NotEqual(field string, value interface{}) Identifier
+	GreaterOrEqual(field string, value interface{}) Identifier
+	LessOrEqual(field string, value interface{}) Identifier
+	NotIn(field string, values []interface{}) Identifier
+	IsNull(field string) Identifier
+	IsNotNull(field string) Identifier
+	Exists(field string, exists bool) Identifier
+	StartsWith(field string, prefix string) Identifier
+	EndsWith(field string, suffix string) Identifier
+	ILike(field string, pattern string) Identifier
+	Regex(field string, pattern string, options string) Identifier
 }
 
 // SortDirection represents sort direction
@@ -48,14 +75,214 @@ const (
 	SortDesc SortDirection = "DESC"
 )
 
-// SortMap defines sorting parameters
+// SortMap defines sorting parameters.
+// Map iteration order is random, so prefer SortSpec when sorting by more than one field.
 type SortMap map[string]SortDirection
 
-// QueryParams defines query parameters for pagination and filtering
+// Spec converts the sort map to a SortSpec ordered by field name
+func (m SortMap) Spec() SortSpec {
+	if len(m) == 0 {
+		return nil
+	}
+
+	spec := make(SortSpec, 0, len(m))
+	for field, direction := range m {
+		spec = append(spec, SortField{Field: field, Direction: direction})
+	}
+	sort.Slice(spec, func(i, j int) bool {
+		return spec[i].Field < spec[j].Field
+	})
+
+	return spec
+}
+
+// NullsOrder places NULL values before or after the other values of a sort field
+type NullsOrder string
+
+const (
+	NullsDefault NullsOrder = ""
+	NullsFirst   NullsOrder = "FIRST"
+	NullsLast    NullsOrder = "LAST"
+)
+
+// SortField sorts by a single field
+type SortField struct {
+	Field     string
+	Direction SortDirection
+	Nulls     NullsOrder
+}
+
+// SortSpec is an ordered sort specification; earlier fields take precedence over later ones
+type SortSpec []SortField
+
+// FindOptions controls the order, size and shape of a FindAllOptions result
+type FindOptions struct {
+	// OrderBy takes precedence over Sort, which is kept for compatibility
+	OrderBy SortSpec
+	Sort    SortMap
+	Limit   int
+	// Projection lists the fields to load; empty loads every field
+	Projection []string
+}
+
+// Ordering returns OrderBy, or the compatibility Sort map when OrderBy is empty
+func (o FindOptions) Ordering() SortSpec {
+	return ordering(o.OrderBy, o.Sort)
+}
+
+// ordering prefers an explicit sort specification over a sort map
+func ordering(spec SortSpec, sortMap SortMap) SortSpec {
+	if len(spec) > 0 {
+		return spec
+	}
+	return sortMap.Spec()
+}
+
+// UpsertOptions controls how Upsert recognizes an existing entity and what it overwrites
+type UpsertOptions struct {
+	// ConflictFields are the PostgreSQL columns of the unique constraint an existing row conflicts
+	// on; empty uses the fields of the filter's equality conditions, or else the primary key.
+	// MongoDB matches the filter instead.
+	ConflictFields []string
+	// UpdateFields are the fields overwritten when the entity exists, along with the modification
+	// time; empty overwrites every field but the primary key and, in PostgreSQL, the creation time
+	UpdateFields []string
+}
+
+// ReturnDocument selects the version of an entity FindOneAndUpdate returns
+type ReturnDocument string
+
+const (
+	// ReturnAfter returns the entity as updated
+	ReturnAfter ReturnDocument = "after"
+	// ReturnBefore returns the entity as it was before the update
+	ReturnBefore ReturnDocument = "before"
+)
+
+// FindAndModifyOptions controls which entity FindOneAndUpdate and FindOneAndDelete pick and what
+// they return
+type FindAndModifyOptions struct {
+	// Return selects the version FindOneAndUpdate returns; empty means ReturnAfter.
+	// FindOneAndDelete always returns the entity as it was.
+	Return ReturnDocument
+	// OrderBy picks the first of several matching entities; empty picks any of them
+	OrderBy SortSpec
+}
+
+// QueryParams defines query parameters for pagination and filtering.
+// Filter matches the non-zero fields of an entity; Where adds identifier conditions
+// such as ranges, IN lists or LIKE on top of it. Both also narrow the total count.
 type QueryParams[T any] struct {
 	Filter  T
+	Where   Identifier
 	Limit   int
 	Offset  int
+	OrderBy SortSpec
 	Sort    SortMap
 	Include []string
 }
+
+// Ordering returns OrderBy, or the compatibility Sort map when OrderBy is empty
+func (p QueryParams[T]) Ordering() SortSpec {
+	return ordering(p.OrderBy, p.Sort)
+}
+
+// IsolationLevel represents a PostgreSQL transaction isolation level
+type IsolationLevel string
+
+const (
+	IsolationDefault         IsolationLevel = ""
+	IsolationReadUncommitted IsolationLevel = "READ UNCOMMITTED"
+	IsolationReadCommitted   IsolationLevel = "READ COMMITTED"
+	IsolationRepeatableRead  IsolationLevel = "REPEATABLE READ"
+	IsolationSerializable    IsolationLevel = "SERIALIZABLE"
+)
+
+// TxOptions configures a transaction. Options that a backend does not support are ignored by it,
+// and nested transactions keep the settings of the transaction they join.
+type TxOptions struct {
+	// PostgreSQL options
+	Isolation IsolationLevel
+	ReadOnly  bool
+
+	// MongoDB options; nil keeps the client's defaults
+	ReadConcern    *readconcern.ReadConcern
+	WriteConcern   *writeconcern.WriteConcern
+	ReadPreference *readpref.ReadPref
+
+	// MaxDuration bounds the whole transaction, including retries, through the context deadline
+	MaxDuration time.Duration
+}
+
+// TxOptionsFrom returns the first non-nil options in opts, or the zero TxOptions
+func TxOptionsFrom(opts []*TxOptions) TxOptions {
+	for _, o := range opts {
+		if o != nil {
+			return *o
+		}
+	}
+	return TxOptions{}
+}
+
+// SQLOptions converts the options to database/sql transaction options
+func (o TxOptions) SQLOptions() *sql.TxOptions {
+	isolation := sql.LevelDefault
+	switch o.Isolation {
+	case IsolationReadUncommitted:
+		isolation = sql.LevelReadUncommitted
+	case IsolationReadCommitted:
+		isolation = sql.LevelReadCommitted
+	case IsolationRepeatableRead:
+		isolation = sql.LevelRepeatableRead
+	case IsolationSerializable:
+		isolation = sql.LevelSerializable
+	}
+
+	return &sql.TxOptions{
+		Isolation: isolation,
+		ReadOnly:  o.ReadOnly,
+	}
+}
+
+// MongoOptions converts the options to MongoDB transaction options
+func (o TxOptions) MongoOptions() *options.TransactionOptions {
+	txOptions := options.Transaction()
+	if o.ReadConcern != nil {
+		txOptions.SetReadConcern(o.ReadConcern)
+	}
+	if o.WriteConcern != nil {
+		txOptions.SetWriteConcern(o.WriteConcern)
+	}
+	if o.ReadPreference != nil {
+		txOptions.SetReadPreference(o.ReadPreference)
+	}
+	return txOptions
+}
+
+// DefaultPageSize is the page size used by FindPage when PageRequest.Limit is not positive
+const DefaultPageSize = 20
+
+// PageRequest asks for one page of a keyset-paginated query
+type PageRequest struct {
+	Where   Identifier
+	OrderBy SortSpec
+	Limit   int
+	// Cursor is the NextCursor or PrevCursor of a previous page; empty requests the first page
+	Cursor string
+}
+
+// PageSize returns Limit, or DefaultPageSize when Limit is not positive
+func (r PageRequest) PageSize() int {
+	if r.Limit > 0 {
+		return r.Limit
+	}
+	return DefaultPageSize
+}
+
+// Page is one page of a keyset-paginated query. The cursors are empty when there is no
+// page in their direction.
+type Page[T any] struct {
+	Items      []T
+	NextCursor string
+	PrevCursor string
+}
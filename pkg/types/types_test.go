@@ -0,0 +1,123 @@
+package types_test
+
+import (
+	"database/sql"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"go.mongodb.org/mongo-driver/mongo/readconcern"
+)
+
+func TestTxOptionsFrom(t *testing.T) {
+	if opts := types.TxOptionsFrom(nil); opts != (types.TxOptions{}) {
+		t.Errorf("Expected zero options, got %+v", opts)
+	}
+
+	want := &types.TxOptions{ReadOnly: true, MaxDuration: time.Second}
+	if opts := types.TxOptionsFrom([]*types.TxOptions{nil, want}); opts != *want {
+		t.Errorf("Expected %+v, got %+v", *want, opts)
+	}
+}
+
+func TestTxOptions_SQLOptions(t *testing.T) {
+	tests := []struct {
+		isolation types.IsolationLevel
+		expected  sql.IsolationLevel
+	}{
+		{types.IsolationDefault, sql.LevelDefault},
+		{types.IsolationReadCommitted, sql.LevelReadCommitted},
+		{types.IsolationRepeatableRead, sql.LevelRepeatableRead},
+		{types.IsolationSerializable, sql.LevelSerializable},
+	}
+
+	for _, tt := range tests {
+		opts := types.TxOptions{Isolation: tt.isolation, ReadOnly: true}.SQLOptions()
+		if opts.Isolation != tt.expected {
+			t.Errorf("Expected isolation %v for %q, got %v", tt.expected, tt.isolation, opts.Isolation)
+		}
+		if !opts.ReadOnly {
+			t.Error("Expected read-only transaction")
+		}
+	}
+}
+
+func TestTxOptions_MongoOptions(t *testing.T) {
+	opts := types.TxOptions{ReadConcern: readconcern.Snapshot()}.MongoOptions()
+
+	if opts.ReadConcern == nil || opts.ReadConcern.Level != "snapshot" {
+		t.Errorf("Expected snapshot read concern, got %v", opts.ReadConcern)
+	}
+
+	if opts.WriteConcern != nil || opts.ReadPreference != nil {
+		t.Error("Unset options should keep the client's defaults")
+	}
+}
+
+func TestSortMap_Spec(t *testing.T) {
+	sortMap := types.SortMap{"last_name": types.SortAsc, "first_name": types.SortDesc, "age": types.SortAsc}
+
+	expected := types.SortSpec{
+		{Field: "age", Direction: types.SortAsc},
+		{Field: "first_name", Direction: types.SortDesc},
+		{Field: "last_name", Direction: types.SortAsc},
+	}
+
+	for i := 0; i < 10; i++ {
+		if spec := sortMap.Spec(); !reflect.DeepEqual(spec, expected) {
+			t.Fatalf("Expected %v, got %v", expected, spec)
+		}
+	}
+}
+
+func TestQueryParams_Ordering(t *testing.T) {
+	orderBy := types.SortSpec{
+		{Field: "last_name", Direction: types.SortAsc, Nulls: types.NullsLast},
+		{Field: "first_name", Direction: types.SortAsc},
+	}
+
+	params := types.QueryParams[any]{
+		OrderBy: orderBy,
+		Sort:    types.SortMap{"id": types.SortDesc},
+	}
+	if ordering := params.Ordering(); !reflect.DeepEqual(ordering, orderBy) {
+		t.Errorf("Expected OrderBy to take precedence, got %v", ordering)
+	}
+
+	params.OrderBy = nil
+	expected := types.SortSpec{{Field: "id", Direction: types.SortDesc}}
+	if ordering := params.Ordering(); !reflect.DeepEqual(ordering, expected) {
+		t.Errorf("Expected the sort map as fallback, got %v", ordering)
+	}
+}
+
+func TestUpdateSet(t *testing.T) {
+	changes := types.NewUpdateSet().
+		Set("name", "Jane").
+		Unset("nickname").
+		Increment("logins", 1).
+		Multiply("score", 1.5).
+		Min("low", 2).
+		Max("high", 9).
+		Push("tags", "a", "b").
+		Pull("roles", "guest")
+
+	expected := types.UpdateSet{
+		"name":     "Jane",
+		"nickname": types.FieldUpdate{Operator: types.OpUnset},
+		"logins":   types.FieldUpdate{Operator: types.OpIncrement, Value: 1},
+		"score":    types.FieldUpdate{Operator: types.OpMultiply, Value: 1.5},
+		"low":      types.FieldUpdate{Operator: types.OpMin, Value: 2},
+		"high":     types.FieldUpdate{Operator: types.OpMax, Value: 9},
+		"tags":     types.FieldUpdate{Operator: types.OpPush, Value: []interface{}{"a", "b"}},
+		"roles":    types.FieldUpdate{Operator: types.OpPull, Value: []interface{}{"guest"}},
+	}
+	if !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Expected %v, got %v", expected, changes)
+	}
+
+	if update := types.AsFieldUpdate("Jane"); update != (types.FieldUpdate{Operator: types.OpSet, Value: "Jane"}) {
+		t.Errorf("Expected a plain value to be set, got %+v", update)
+	}
+}
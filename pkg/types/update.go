@@ -0,0 +1,101 @@
+package types
+
+// UpdateOperator is the operation a change applies to a field
+type UpdateOperator string
+
+const (
+	// OpSet assigns the value
+	OpSet UpdateOperator = "set"
+	// OpUnset removes the field, or sets the column to NULL
+	OpUnset UpdateOperator = "unset"
+	// OpIncrement adds the value to the field, treating a missing field as zero
+	OpIncrement UpdateOperator = "inc"
+	// OpMultiply multiplies the field by the value, treating a missing field as zero
+	OpMultiply UpdateOperator = "mul"
+	// OpMin assigns the value when it is less than the field
+	OpMin UpdateOperator = "min"
+	// OpMax assigns the value when it is greater than the field
+	OpMax UpdateOperator = "max"
+	// OpPush appends the values to the array field
+	OpPush UpdateOperator = "push"
+	// OpPull removes every occurrence of the values from the array field
+	OpPull UpdateOperator = "pull"
+)
+
+// FieldUpdate is a change to a field computed from its current value. OpPush and OpPull take
+// a []interface{} of values.
+type FieldUpdate struct {
+	Operator UpdateOperator
+	Value    interface{}
+}
+
+// AsFieldUpdate returns the change a value of an UpdateSet makes: a FieldUpdate as is, and any
+// other value assigned with OpSet
+func AsFieldUpdate(value interface{}) FieldUpdate {
+	if update, ok := value.(FieldUpdate); ok {
+		return update
+	}
+	return FieldUpdate{Operator: OpSet, Value: value}
+}
+
+// UpdateSet maps fields to their changes: a plain value is assigned, a FieldUpdate applies its
+// operator. Fields are named as in filters. The builder methods modify the set and return it:
+//
+//	changes := types.NewUpdateSet().
+//		Set("name", "Jane").
+//		Increment("logins", 1).
+//		Push("tags", "admin")
+type UpdateSet map[string]interface{}
+
+// NewUpdateSet returns an empty update set
+func NewUpdateSet() UpdateSet {
+	return UpdateSet{}
+}
+
+// Set assigns value to field
+func (s UpdateSet) Set(field string, value interface{}) UpdateSet {
+	s[field] = value
+	return s
+}
+
+// Unset removes field, or sets its column to NULL
+func (s UpdateSet) Unset(field string) UpdateSet {
+	s[field] = FieldUpdate{Operator: OpUnset}
+	return s
+}
+
+// Increment adds by to field
+func (s UpdateSet) Increment(field string, by interface{}) UpdateSet {
+	s[field] = FieldUpdate{Operator: OpIncrement, Value: by}
+	return s
+}
+
+// Multiply multiplies field by factor
+func (s UpdateSet) Multiply(field string, factor interface{}) UpdateSet {
+	s[field] = FieldUpdate{Operator: OpMultiply, Value: factor}
+	return s
+}
+
+// Min assigns value to field when it is less than the current value
+func (s UpdateSet) Min(field string, value interface{}) UpdateSet {
+	s[field] = FieldUpdate{Operator: OpMin, Value: value}
+	return s
+}
+
+// Max assigns value to field when it is greater than the current value
+func (s UpdateSet) Max(field string, value interface{}) UpdateSet {
+	s[field] = FieldUpdate{Operator: OpMax, Value: value}
+	return s
+}
+
+// Push appends values to the array field
+func (s UpdateSet) Push(field string, values ...interface{}) UpdateSet {
+	s[field] = FieldUpdate{Operator: OpPush, Value: values}
+	return s
+}
+
+// Pull removes every occurrence of values from the array field
+func (s UpdateSet) Pull(field string, values ...interface{}) UpdateSet {
+	s[field] = FieldUpdate{Operator: OpPull, Value: values}
+	return s
+}
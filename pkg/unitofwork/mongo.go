@@ -0,0 +1,86 @@
+package unitofwork
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/interfaces"
+	"github.com/arash-mosavi/go-base-repository/pkg/mongo"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	mongoDriver "go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoUnitOfWork shares one MongoDB session and transaction between repositories.
+// MongoDB transactions require a replica set or sharded cluster.
+type MongoUnitOfWork struct {
+	database *mongoDriver.Database
+	session  mongoDriver.Session
+	ctx      context.Context
+	cancel   context.CancelFunc
+	finished bool
+}
+
+// NewMongo starts a session and transaction on the database's client with the concerns,
+// read preference and maximum duration from opts and returns the unit of work owning them
+func NewMongo(ctx context.Context, database *mongoDriver.Database, opts ...*types.TxOptions) (*MongoUnitOfWork, error) {
+	txOptions := types.TxOptionsFrom(opts)
+
+	session, err := database.Client().StartSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+
+	if err := session.StartTransaction(txOptions.MongoOptions()); err != nil {
+		session.EndSession(ctx)
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	ctx, cancel := withMaxDuration(ctx, txOptions.MaxDuration)
+	return &MongoUnitOfWork{
+		database: database,
+		session:  session,
+		ctx:      mongoDriver.NewSessionContext(ctx, session),
+		cancel:   cancel,
+	}, nil
+}
+
+// MongoRepository returns a base repository for T in the unit of work's database.
+// Calls take part in the transaction when made with the unit of work's Context.
+func MongoRepository[T types.MongoEntity](u *MongoUnitOfWork) interfaces.MongoBaseRepository[T] {
+	return mongo.NewBaseRepository[T](u.database)
+}
+
+// Context returns the context carrying the session
+func (u *MongoUnitOfWork) Context() context.Context {
+	return u.ctx
+}
+
+// Commit commits the transaction and ends the session
+func (u *MongoUnitOfWork) Commit() error {
+	if u.finished {
+		return ErrFinished
+	}
+	u.finished = true
+	defer u.cancel()
+	defer u.session.EndSession(context.WithoutCancel(u.ctx))
+
+	if err := u.session.CommitTransaction(u.ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Rollback aborts the transaction and ends the session unless it has already finished
+func (u *MongoUnitOfWork) Rollback() error {
+	if u.finished {
+		return nil
+	}
+	u.finished = true
+	defer u.cancel()
+	defer u.session.EndSession(context.WithoutCancel(u.ctx))
+
+	if err := u.session.AbortTransaction(context.WithoutCancel(u.ctx)); err != nil {
+		return fmt.Errorf("failed to abort transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package unitofwork_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/unitofwork"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// Customer is a MongoDB entity
+type Customer struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	Slug      string             `bson:"slug" json:"slug"`
+	CreatedAt time.Time          `bson:"createdAt" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updatedAt" json:"updated_at"`
+	DeletedAt *time.Time         `bson:"deletedAt,omitempty" json:"deleted_at,omitempty"`
+}
+
+func (c *Customer) GetID() primitive.ObjectID   { return c.ID }
+func (c *Customer) SetID(id primitive.ObjectID) { c.ID = id }
+func (c *Customer) GetSlug() string             { return c.Slug }
+func (c *Customer) SetSlug(slug string)         { c.Slug = slug }
+func (c *Customer) GetName() string             { return c.Name }
+func (c *Customer) SetName(name string)         { c.Name = name }
+func (c *Customer) GetCreatedAt() time.Time     { return c.CreatedAt }
+func (c *Customer) GetUpdatedAt() time.Time     { return c.UpdatedAt }
+func (c *Customer) GetDeletedAt() *time.Time    { return c.DeletedAt }
+func (c *Customer) SetCreatedAt(t time.Time)    { c.CreatedAt = t }
+func (c *Customer) SetUpdatedAt(t time.Time)    { c.UpdatedAt = t }
+func (c *Customer) SetDeletedAt(t *time.Time)   { c.DeletedAt = t }
+func (c *Customer) IsDeleted() bool             { return c.DeletedAt != nil }
+
+// Ticket is a second MongoDB entity
+type Ticket struct {
+	Customer `bson:",inline"`
+}
+
+// started returns the next command sent to the mock deployment, failing when it is not named name
+func started(mt *mtest.T, name string) bson.Raw {
+	mt.Helper()
+
+	event := mt.GetStartedEvent()
+	if event == nil {
+		mt.Fatalf("Expected a %s command, got none", name)
+	}
+	if event.CommandName != name {
+		mt.Fatalf("Expected a %s command, got %s", name, event.CommandName)
+	}
+	return event.Command
+}
+
+func TestMongoUnitOfWork(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("commit", func(mt *mtest.T) {
+		uow, err := unitofwork.NewMongo(context.Background(), mt.DB)
+		if err != nil {
+			mt.Fatalf("Failed to start unit of work: %v", err)
+		}
+		defer uow.Rollback()
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+		if _, err := unitofwork.MongoRepository[*Customer](uow).Insert(uow.Context(), &Customer{Name: "customer"}); err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		if _, err := unitofwork.MongoRepository[*Ticket](uow).Insert(uow.Context(), &Ticket{Customer{Name: "ticket"}}); err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		if err := uow.Commit(); err != nil {
+			mt.Fatalf("Failed to commit: %v", err)
+		}
+
+		customers := started(mt, "insert")
+		tickets := started(mt, "insert")
+		commit := started(mt, "commitTransaction")
+
+		if collection := customers.Lookup("insert").StringValue(); collection != "customers" {
+			mt.Errorf("Expected an insert into customers, got %s", collection)
+		}
+		if collection := tickets.Lookup("insert").StringValue(); collection != "tickets" {
+			mt.Errorf("Expected an insert into tickets, got %s", collection)
+		}
+		if begun, ok := customers.Lookup("startTransaction").BooleanOK(); !ok || !begun {
+			mt.Errorf("Expected the first insert to start the transaction, got %v", customers)
+		}
+		if _, err := tickets.LookupErr("startTransaction"); err == nil {
+			mt.Errorf("Expected the second insert to join the transaction, got %v", tickets)
+		}
+		for _, cmd := range []bson.Raw{tickets, commit} {
+			if !cmd.Lookup("lsid").Equal(customers.Lookup("lsid")) || !cmd.Lookup("txnNumber").Equal(customers.Lookup("txnNumber")) {
+				mt.Errorf("Expected one session and transaction, got %v and %v", customers, cmd)
+			}
+		}
+
+		if err := uow.Commit(); !errors.Is(err, unitofwork.ErrFinished) {
+			mt.Errorf("Expected ErrFinished, got %v", err)
+		}
+		if err := uow.Rollback(); err != nil {
+			mt.Errorf("Expected rollback after commit to be a no-op, got %v", err)
+		}
+		if uow.Context().Err() == nil {
+			mt.Error("Expected the context to be cancelled once the unit of work finished")
+		}
+		if _, err := unitofwork.MongoRepository[*Customer](uow).Insert(uow.Context(), &Customer{Name: "late"}); err == nil {
+			mt.Error("Expected an insert through the finished unit of work to fail")
+		}
+		if event := mt.GetStartedEvent(); event != nil {
+			mt.Errorf("Expected no commands after commit, got %s", event.CommandName)
+		}
+	})
+}
+
+func TestMongoUnitOfWork_Rollback(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("rollback", func(mt *mtest.T) {
+		uow, err := unitofwork.NewMongo(context.Background(), mt.DB)
+		if err != nil {
+			mt.Fatalf("Failed to start unit of work: %v", err)
+		}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+		if _, err := unitofwork.MongoRepository[*Customer](uow).Insert(uow.Context(), &Customer{Name: "customer"}); err != nil {
+			mt.Fatalf("Unexpected error: %v", err)
+		}
+		if err := uow.Rollback(); err != nil {
+			mt.Fatalf("Failed to roll back: %v", err)
+		}
+
+		insert := started(mt, "insert")
+		abort := started(mt, "abortTransaction")
+		if !abort.Lookup("lsid").Equal(insert.Lookup("lsid")) || !abort.Lookup("txnNumber").Equal(insert.Lookup("txnNumber")) {
+			mt.Errorf("Expected the transaction of the insert to be aborted, got %v", abort)
+		}
+
+		if err := uow.Commit(); !errors.Is(err, unitofwork.ErrFinished) {
+			mt.Errorf("Expected ErrFinished, got %v", err)
+		}
+		if uow.Context().Err() == nil {
+			mt.Error("Expected the context to be cancelled once the unit of work finished")
+		}
+	})
+}
@@ -0,0 +1,76 @@
+package unitofwork
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/arash-mosavi/go-base-repository/pkg/interfaces"
+	"github.com/arash-mosavi/go-base-repository/pkg/postgres"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"gorm.io/gorm"
+)
+
+// PostgresUnitOfWork shares one GORM transaction between PostgreSQL repositories
+type PostgresUnitOfWork struct {
+	tx       *gorm.DB
+	ctx      context.Context
+	cancel   context.CancelFunc
+	finished bool
+}
+
+// NewPostgres begins a transaction on db with the isolation level, access mode and
+// maximum duration from opts and returns the unit of work owning it
+func NewPostgres(ctx context.Context, db *gorm.DB, opts ...*types.TxOptions) (*PostgresUnitOfWork, error) {
+	txOptions := types.TxOptionsFrom(opts)
+	ctx, cancel := withMaxDuration(ctx, txOptions.MaxDuration)
+
+	tx := db.WithContext(ctx).Begin(txOptions.SQLOptions())
+	if tx.Error != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to begin transaction: %w", tx.Error)
+	}
+
+	return &PostgresUnitOfWork{
+		tx:     tx,
+		ctx:    postgres.ContextWithTx(ctx, tx),
+		cancel: cancel,
+	}, nil
+}
+
+// PostgresRepository returns a base repository for T that runs on the unit of work's transaction
+func PostgresRepository[T types.PostgresEntity](u *PostgresUnitOfWork) interfaces.PostgresBaseRepository[T] {
+	return postgres.NewBaseRepository[T](u.tx)
+}
+
+// Context returns the context carrying the transaction
+func (u *PostgresUnitOfWork) Context() context.Context {
+	return u.ctx
+}
+
+// Commit commits the transaction
+func (u *PostgresUnitOfWork) Commit() error {
+	if u.finished {
+		return ErrFinished
+	}
+	u.finished = true
+	defer u.cancel()
+
+	if err := u.tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Rollback rolls the transaction back unless it has already finished
+func (u *PostgresUnitOfWork) Rollback() error {
+	if u.finished {
+		return nil
+	}
+	u.finished = true
+	defer u.cancel()
+
+	if err := u.tx.Rollback().Error; err != nil {
+		return fmt.Errorf("failed to rollback transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package unitofwork_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arash-mosavi/go-base-repository/internal/fakesql"
+	"github.com/arash-mosavi/go-base-repository/pkg/types"
+	"github.com/arash-mosavi/go-base-repository/pkg/unitofwork"
+	"gorm.io/gorm"
+)
+
+// Order is a PostgreSQL entity
+type Order struct {
+	ID        int            `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string         `json:"name"`
+	Slug      string         `json:"slug"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at"`
+}
+
+func (o *Order) GetID() int                    { return o.ID }
+func (o *Order) GetSlug() string               { return o.Slug }
+func (o *Order) SetSlug(slug string)           { o.Slug = slug }
+func (o *Order) GetCreatedAt() time.Time       { return o.CreatedAt }
+func (o *Order) GetUpdatedAt() time.Time       { return o.UpdatedAt }
+func (o *Order) GetArchivedAt() gorm.DeletedAt { return o.DeletedAt }
+func (o *Order) GetName() string               { return o.Name }
+
+// Invoice is a second PostgreSQL entity
+type Invoice struct {
+	Order
+}
+
+func TestPostgresUnitOfWork(t *testing.T) {
+	db, fake := fakesql.Open(t)
+
+	uow, err := unitofwork.NewPostgres(context.Background(), db, &types.TxOptions{Isolation: types.IsolationSerializable})
+	if err != nil {
+		t.Fatalf("Failed to start unit of work: %v", err)
+	}
+	defer uow.Rollback()
+
+	if _, err := unitofwork.PostgresRepository[*Order](uow).Insert(uow.Context(), &Order{Name: "order"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := unitofwork.PostgresRepository[*Invoice](uow).Insert(uow.Context(), &Invoice{Order{Name: "invoice"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := uow.Commit(); err != nil {
+		t.Fatalf("Failed to commit: %v", err)
+	}
+
+	var got []string
+	for _, sql := range fake.SQL() {
+		got = append(got, strings.SplitN(sql, " (", 2)[0])
+	}
+	expected := []string{
+		"BEGIN ISOLATION LEVEL SERIALIZABLE",
+		`INSERT INTO "orders"`,
+		`INSERT INTO "invoices"`,
+		"COMMIT",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+
+	if err := uow.Commit(); !errors.Is(err, unitofwork.ErrFinished) {
+		t.Errorf("Expected ErrFinished, got %v", err)
+	}
+	if err := uow.Rollback(); err != nil {
+		t.Errorf("Expected rollback after commit to be a no-op, got %v", err)
+	}
+}
+
+func TestPostgresUnitOfWork_Rollback(t *testing.T) {
+	db, fake := fakesql.Open(t)
+
+	uow, err := unitofwork.NewPostgres(context.Background(), db, &types.TxOptions{ReadOnly: true})
+	if err != nil {
+		t.Fatalf("Failed to start unit of work: %v", err)
+	}
+	if err := uow.Rollback(); err != nil {
+		t.Fatalf("Failed to roll back: %v", err)
+	}
+
+	expected := []string{"BEGIN READ ONLY", "ROLLBACK"}
+	if got := fake.SQL(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if uow.Context().Err() == nil {
+		t.Error("Expected the context to be cancelled once the unit of work finished")
+	}
+}
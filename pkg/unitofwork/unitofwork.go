@@ -0,0 +1,30 @@
+// Package unitofwork coordinates writes to several entity types inside one transaction
+package unitofwork
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrFinished is returned when a unit of work is committed after it has already been committed or rolled back
+var ErrFinished = errors.New("unit of work already committed or rolled back")
+
+// UnitOfWork is a transaction shared by repositories of different entity types
+type UnitOfWork interface {
+	// Context returns the context repository calls must use to take part in the transaction
+	Context() context.Context
+	// Commit commits every change made through the unit of work
+	Commit() error
+	// Rollback discards every change made through the unit of work. It is a no-op once the
+	// unit of work has been committed, so it is safe to defer.
+	Rollback() error
+}
+
+// withMaxDuration derives a context that expires after d, or a cancelable copy of ctx when d is not positive
+func withMaxDuration(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return context.WithCancel(ctx)
+}